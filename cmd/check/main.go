@@ -14,18 +14,42 @@ import (
 	"unsafe"
 
 	"github.com/aaa2ppp/multgen/internal/checker"
+	"github.com/aaa2ppp/multgen/internal/player"
+	"github.com/aaa2ppp/multgen/internal/solver"
 )
 
 var (
 	help       = flag.Bool("help", false, "show usage help")
-	minX       = flag.Float64("min", 1, "min sequence value, must be >= 1.0")
-	maxX       = flag.Float64("max", 10000, "max sequence value, must be >= 1.0")
+	minX       = flag.Float64("min", solver.DefaultMin, "min sequence value, must be >= 1.0")
+	maxX       = flag.Float64("max", solver.DefaultMax, "max sequence value, must be >= 1.0")
 	one        = flag.Bool("1", false, "if this flag is set, then payment = 1")
 	multiply   = flag.Bool("m", false, "if this flag is set, then transform = x * m, otherwise x")
 	playersNum = flag.Int("n", 1, "number of playes")
+	strategy   = flag.String("strategy", player.DefaultStrategy, "how each player chooses x in [min, max]:"+player.Help())
 	verbose    = flag.Bool("v", false, "output human-readable results in stderr")
+	format     = flag.String("format", formatText, "output format: text, json or csv")
+
+	// RTP assertion
+	expectRTP = flag.Float64("expect-rtp", 0, "expected RTP; if set, print a verdict and exit non-zero when it fails")
+	tolerance = flag.Float64("tolerance", 0, "allowed deviation of the expected RTP from the confidence interval (required with one player)")
+	verdictCL = flag.Float64("cl", 0.95, "confidence level of the -expect-rtp interval")
+
+	// bankroll mode
+	bankroll      = flag.Float64("bankroll", 0, "start balance of each player (0 - no bankroll); the bet replaces the payment, a win pays bet*x (bet*x*m with -m)")
+	stakeAmount   = flag.Float64("stake", 1, "bet per round in bankroll mode (min bet, if -stake-fraction is set)")
+	stakeFraction = flag.Float64("stake-fraction", 0, "bet a fraction of the current balance in bankroll mode")
+	rounds        = flag.Int("rounds", 0, "max session length in bankroll mode; 0 - until the input ends")
 )
 
+func bankrollFlags() checker.Bankroll {
+	return checker.Bankroll{
+		Start:    *bankroll,
+		Stake:    *stakeAmount,
+		Fraction: *stakeFraction,
+		Rounds:   *rounds,
+	}
+}
+
 func validateFlags() error {
 	var errs []error
 
@@ -41,6 +65,40 @@ func validateFlags() error {
 		errs = append(errs, errors.New("number of playesr must be >= 1"))
 	}
 
+	switch *format {
+	case formatText, formatJSON, formatCSV:
+	default:
+		errs = append(errs, fmt.Errorf("unknown output format %q", *format))
+	}
+
+	if *expectRTP != 0 {
+		if !(*expectRTP > 0) {
+			errs = append(errs, errors.New("expected RTP must be > 0"))
+		}
+		if !(*tolerance >= 0) {
+			errs = append(errs, errors.New("tolerance must be >= 0"))
+		}
+		if !(*verdictCL > 0 && *verdictCL < 1) {
+			errs = append(errs, errors.New("confidence level must be in (0, 1)"))
+		}
+		// с одним игроком интервал вырождается в точку
+		if (*playersNum == 1 || *minX == *maxX) && *tolerance == 0 {
+			errs = append(errs, errors.New("-expect-rtp with one player (-n=1 or min = max) needs -tolerance > 0"))
+		}
+	}
+
+	if *bankroll != 0 {
+		if err := bankrollFlags().Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) == 0 {
+		if _, err := player.Parse(*strategy, *minX, *maxX); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
 	return errors.Join(errs...)
 }
 
@@ -63,7 +121,11 @@ func main() {
 	w := bufio.NewWriter(os.Stdout)
 	defer w.Flush()
 
-	type player struct {
+	type gambler struct {
+		strategy     player.Strategy
+		observer     player.Observer // nil - стратегия не зависит от истории
+		staker       player.Staker   // nil - ставка 1
+		session      checker.Session // сессия в режиме банкролла
 		totalPayment float64
 		totalProfit  float64
 	}
@@ -72,15 +134,24 @@ func main() {
 		*playersNum = 1
 	}
 
-	log.Printf("players=%d", *playersNum)
-	players := make([]player, *playersNum)
+	log.Printf("players=%d strategy=%s", *playersNum, *strategy)
+	newStrategy, _ := player.Parse(*strategy, *minX, *maxX) // проверена в validateFlags
+	bank := bankrollFlags()
+	players := make([]gambler, *playersNum)
+	for i := range players {
+		players[i].session = checker.NewSession(bank)
+		s := newStrategy(rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())))
+		players[i].strategy = s
+		players[i].observer, _ = s.(player.Observer)
+		players[i].staker, _ = s.(player.Staker)
+	}
 
 	var (
 		m             float64 // мультипликатор
 		x             float64 // значение последовательности
 		p             = 1.0   // платеж
+		stake         float64 // размер ставки
 		t             float64 // значение после трансформации
-		d             = *maxX - *minX
 		count         int
 		maxMultiplier float64
 		err           error
@@ -96,15 +167,13 @@ func main() {
 		}
 
 		for i := range players {
-			// get an element of a "random" sequence
-			x = *minX
-			if d > 0 {
-				x += rand.Float64() * d
+			// the player has left the game
+			if *bankroll != 0 && !players[i].session.Active(bank) {
+				continue
 			}
 
-			if !*one {
-				p = x
-			}
+			// get an element of the player's sequence
+			x = players[i].strategy.X()
 
 			// transformate t = F(m, x)
 			if m <= x {
@@ -115,9 +184,30 @@ func main() {
 				t = x * m
 			}
 
+			stake = 1
+			if players[i].staker != nil {
+				stake = players[i].staker.Stake()
+			}
+
+			if *bankroll != 0 {
+				// the bet replaces the payment, a win pays bet*t regardless of -1
+				p, t = players[i].session.Play(bank, stake, t)
+			} else {
+				p = 1
+				if !*one {
+					p = x
+				}
+				p, t = p*stake, t*stake
+			}
+
 			// count player aggregates
 			players[i].totalPayment += p
 			players[i].totalProfit += t
+
+			// let the player see the outcome
+			if players[i].observer != nil {
+				players[i].observer.Observe(m, m > x)
+			}
 		}
 
 		// count common aggregates
@@ -129,43 +219,55 @@ func main() {
 		log.Fatal(err)
 	}
 
-	// backward compatibility
-	if len(players) == 1 {
-		totalPayment := players[0].totalPayment
-		totalProfit := players[0].totalProfit
-
-		rtp := totalProfit / totalPayment
-
-		if *verbose {
-			log.Printf("count=%d elapsed=%v payment=%0.3f profit=%0.3f max_multiplier=%g",
-				count, time.Since(start), totalPayment, totalProfit, maxMultiplier)
-		}
-
-		w.WriteString(strconv.FormatFloat(rtp, 'g', -1, 64))
-		w.WriteByte('\n')
-		return
+	rep := report{
+		Config:        flagValues(),
+		Count:         count,
+		Elapsed:       time.Since(start).Seconds(),
+		MaxMultiplier: maxMultiplier,
+		Players:       make([]playerReport, len(players)),
 	}
 
 	rtps := make([]float64, len(players))
 	for i := range players {
 		rtps[i] = players[i].totalProfit / players[i].totalPayment
+		rep.Players[i] = playerReport{number(rtps[i]), players[i].totalPayment, players[i].totalProfit}
 	}
 
-	if *verbose {
-		log.Printf("count=%d elapsed=%v max_multiplier=%g",
-			count, time.Since(start), maxMultiplier)
+	if rep.CI, err = confidenceIntervals(rtps); err != nil {
+		log.Fatal(err)
+	}
+
+	if *bankroll != 0 {
+		sessions := make([]checker.Session, len(players))
+		for i := range players {
+			sessions[i] = players[i].session
+		}
+		if rep.Bankroll, err = newBankrollReport(sessions); err != nil {
+			log.Fatal(err)
+		}
 	}
 
-	for _, cl := range []float64{0.90, 0.95, 0.99} {
-		rtp, rtpLo, rtpHi, err := checker.ConfidenceInterval(rtps, cl)
+	if *expectRTP != 0 {
+		v, err := checker.Verify(rtps, *expectRTP, *verdictCL, *tolerance)
 		if err != nil {
 			log.Fatal(err)
 		}
+		rep.Verdict = newVerdict(v)
+	}
 
-		fmt.Printf("%g %g %g %g\n", rtp, rtpLo, rtpHi, cl)
+	if *verbose {
+		logReport(&rep)
+	}
+
+	if err := writeReport(w, &rep, *format); err != nil {
+		log.Fatal(err)
+	}
 
-		if *verbose {
-			log.Printf("%s %g%%\n", formatRangePretty(rtpLo, rtpHi), cl*100)
+	if rep.Verdict != nil {
+		w.Flush() // вердикт - после отчёта
+		fmt.Fprintln(os.Stderr, rep.Verdict)
+		if !rep.Verdict.Passed {
+			os.Exit(1)
 		}
 	}
 }
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/aaa2ppp/multgen/internal/checker"
+)
+
+// Форматы вывода (-format)
+const (
+	formatText = "text"
+	formatJSON = "json"
+	formatCSV  = "csv"
+)
+
+var confidenceLevels = []float64{0.90, 0.95, 0.99}
+
+// number — float64, который в JSON пишется как null, если не конечен
+// (RTP пустого входа — 0/0 = NaN; encoding/json такие значения не пишет).
+type number float64
+
+func (n number) MarshalJSON() ([]byte, error) {
+	if f := float64(n); math.IsNaN(f) || math.IsInf(f, 0) {
+		return []byte("null"), nil
+	}
+	return json.Marshal(float64(n))
+}
+
+// report — результат проверки одной последовательности.
+type report struct {
+	Config        map[string]string `json:"config"` // значения всех флагов
+	Count         int               `json:"count"`
+	Elapsed       float64           `json:"elapsed"` // секунды
+	MaxMultiplier float64           `json:"max_multiplier"`
+	Players       []playerReport    `json:"players"`
+	CI            []interval        `json:"ci"` // RTP по игрокам
+	Bankroll      *bankrollReport   `json:"bankroll,omitempty"`
+	Verdict       *verdict          `json:"verdict,omitempty"` // -expect-rtp
+}
+
+type playerReport struct {
+	RTP     number  `json:"rtp"`
+	Payment float64 `json:"payment"`
+	Profit  float64 `json:"profit"`
+}
+
+// interval — среднее и доверительный интервал для уровня CL.
+type interval struct {
+	CL   float64 `json:"cl"`
+	Mean number  `json:"mean"`
+	Lo   number  `json:"lo"`
+	Hi   number  `json:"hi"`
+}
+
+// verdict — checker.Verdict для отчёта.
+type verdict struct {
+	Expected  float64 `json:"expected"`
+	CL        float64 `json:"cl"`
+	Tolerance float64 `json:"tolerance"`
+	RTP       number  `json:"rtp"`
+	Lo        number  `json:"lo"`
+	Hi        number  `json:"hi"`
+	Passed    bool    `json:"passed"`
+
+	text string // checker.Verdict.String()
+}
+
+func newVerdict(v checker.Verdict) *verdict {
+	return &verdict{
+		Expected:  v.Expected,
+		CL:        v.CL,
+		Tolerance: v.Tolerance,
+		RTP:       number(v.RTP),
+		Lo:        number(v.Lo),
+		Hi:        number(v.Hi),
+		Passed:    v.Passed,
+		text:      v.String(),
+	}
+}
+
+func (v *verdict) String() string { return v.text }
+
+type bankrollReport struct {
+	Ruin    []interval           `json:"ruin"`
+	Length  checker.Distribution `json:"length"`
+	Balance checker.Distribution `json:"balance"`
+}
+
+func flagValues() map[string]string {
+	config := make(map[string]string)
+	flag.VisitAll(func(f *flag.Flag) {
+		config[f.Name] = f.Value.String()
+	})
+	return config
+}
+
+func confidenceIntervals(values []float64) ([]interval, error) {
+	var intervals []interval
+	for _, cl := range confidenceLevels {
+		mean, lo, hi, err := checker.ConfidenceInterval(values, cl)
+		if err != nil {
+			return nil, err
+		}
+		intervals = append(intervals, interval{cl, number(mean), number(lo), number(hi)})
+	}
+	return intervals, nil
+}
+
+func newBankrollReport(sessions []checker.Session) (*bankrollReport, error) {
+	ruins := make([]float64, len(sessions))
+	lengths := make([]float64, len(sessions))
+	balances := make([]float64, len(sessions))
+	for i, s := range sessions {
+		if s.Ruined {
+			ruins[i] = 1
+		}
+		lengths[i] = float64(s.Rounds)
+		balances[i] = s.Balance
+	}
+
+	var (
+		r   bankrollReport
+		err error
+	)
+
+	if r.Ruin, err = confidenceIntervals(ruins); err != nil {
+		return nil, err
+	}
+	for i := range r.Ruin {
+		r.Ruin[i].Lo = number(max(r.Ruin[i].Lo, 0))
+		r.Ruin[i].Hi = number(min(r.Ruin[i].Hi, 1))
+	}
+
+	if r.Length, err = checker.Describe(lengths); err != nil {
+		return nil, err
+	}
+	if r.Balance, err = checker.Describe(balances); err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// logReport выводит отчёт в человекочитаемом виде (-v).
+func logReport(r *report) {
+	if len(r.Players) == 1 {
+		log.Printf("count=%d elapsed=%v payment=%0.3f profit=%0.3f max_multiplier=%g",
+			r.Count, seconds(r.Elapsed), r.Players[0].Payment, r.Players[0].Profit, r.MaxMultiplier)
+	} else {
+		log.Printf("count=%d elapsed=%v max_multiplier=%g",
+			r.Count, seconds(r.Elapsed), r.MaxMultiplier)
+
+		for _, ci := range r.CI {
+			log.Printf("%s %g%%\n", formatRangePretty(float64(ci.Lo), float64(ci.Hi)), ci.CL*100)
+		}
+	}
+
+	if r.Bankroll == nil {
+		return
+	}
+
+	for _, ci := range r.Bankroll.Ruin {
+		log.Printf("ruin %s %g%%\n", formatRangePretty(float64(ci.Lo), float64(ci.Hi)), ci.CL*100)
+	}
+	for _, d := range []struct {
+		name string
+		dist checker.Distribution
+	}{
+		{"length", r.Bankroll.Length},
+		{"balance", r.Bankroll.Balance},
+	} {
+		log.Printf("%s mean=%g min=%g p5=%g p25=%g median=%g p75=%g p95=%g max=%g",
+			d.name, d.dist.Mean, d.dist.Min, d.dist.P5, d.dist.P25, d.dist.P50, d.dist.P75, d.dist.P95, d.dist.Max)
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+func writeReport(w *bufio.Writer, r *report, format string) error {
+	switch format {
+	case formatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case formatCSV:
+		return writeCSV(w, r)
+	default:
+		writeText(w, r)
+		return nil
+	}
+}
+
+// writeText — исходный формат check:
+//
+//	rtp                                      (один игрок)
+//	rtp lo hi cl                             (по строке на уровень доверия)
+//	ruin p lo hi cl                          (режим банкролла)
+//	length mean min p5 p25 p50 p75 p95 max
+//	balance mean min p5 p25 p50 p75 p95 max
+func writeText(w *bufio.Writer, r *report) {
+	// backward compatibility
+	if len(r.Players) == 1 {
+		w.WriteString(strconv.FormatFloat(float64(r.Players[0].RTP), 'g', -1, 64))
+		w.WriteByte('\n')
+	} else {
+		for _, ci := range r.CI {
+			fmt.Fprintf(w, "%g %g %g %g\n", ci.Mean, ci.Lo, ci.Hi, ci.CL)
+		}
+	}
+
+	if r.Bankroll == nil {
+		return
+	}
+
+	for _, ci := range r.Bankroll.Ruin {
+		fmt.Fprintf(w, "ruin %g %g %g %g\n", ci.Mean, ci.Lo, ci.Hi, ci.CL)
+	}
+	for _, d := range []struct {
+		name string
+		dist checker.Distribution
+	}{
+		{"length", r.Bankroll.Length},
+		{"balance", r.Bankroll.Balance},
+	} {
+		w.WriteString(d.name)
+		for _, v := range d.dist.Values() {
+			w.WriteByte(' ')
+			w.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+		}
+		w.WriteByte('\n')
+	}
+}
+
+// writeCSV выводит отчёт парами key,value; ключи повторяют путь в JSON
+// (config.min, ci.0.95.lo, players.3.rtp, bankroll.length.p50, ...).
+func writeCSV(w *bufio.Writer, r *report) error {
+	cw := csv.NewWriter(w)
+	write := func(key string, v float64) {
+		cw.Write([]string{key, strconv.FormatFloat(v, 'g', -1, 64)})
+	}
+	writeNumber := func(key string, v number) { write(key, float64(v)) }
+	writeIntervals := func(prefix string, intervals []interval) {
+		for _, ci := range intervals {
+			cl := prefix + strconv.FormatFloat(ci.CL, 'g', -1, 64)
+			writeNumber(cl+".mean", ci.Mean)
+			writeNumber(cl+".lo", ci.Lo)
+			writeNumber(cl+".hi", ci.Hi)
+		}
+	}
+	writeDistribution := func(prefix string, d checker.Distribution) {
+		values := d.Values()
+		for i, name := range []string{"mean", "min", "p5", "p25", "p50", "p75", "p95", "max"} {
+			write(prefix+name, values[i])
+		}
+	}
+
+	cw.Write([]string{"key", "value"})
+
+	for _, name := range slices.Sorted(maps.Keys(r.Config)) {
+		cw.Write([]string{"config." + name, r.Config[name]})
+	}
+
+	write("count", float64(r.Count))
+	write("elapsed", r.Elapsed)
+	write("max_multiplier", r.MaxMultiplier)
+	writeIntervals("ci.", r.CI)
+	for i, p := range r.Players {
+		prefix := "players." + strconv.Itoa(i) + "."
+		writeNumber(prefix+"rtp", p.RTP)
+		write(prefix+"payment", p.Payment)
+		write(prefix+"profit", p.Profit)
+	}
+
+	if r.Bankroll != nil {
+		writeIntervals("bankroll.ruin.", r.Bankroll.Ruin)
+		writeDistribution("bankroll.length.", r.Bankroll.Length)
+		writeDistribution("bankroll.balance.", r.Bankroll.Balance)
+	}
+
+	if v := r.Verdict; v != nil {
+		write("verdict.expected", v.Expected)
+		write("verdict.cl", v.CL)
+		write("verdict.tolerance", v.Tolerance)
+		writeNumber("verdict.rtp", v.RTP)
+		writeNumber("verdict.lo", v.Lo)
+		writeNumber("verdict.hi", v.Hi)
+		cw.Write([]string{"verdict.passed", strconv.FormatBool(v.Passed)})
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/aaa2ppp/be"
+
+	"github.com/aaa2ppp/multgen/internal/checker"
+)
+
+func testReport() *report {
+	return &report{
+		Config:        map[string]string{"n": "2", "strategy": "fixed:2"},
+		Count:         10,
+		Elapsed:       0.5,
+		MaxMultiplier: 7,
+		Players: []playerReport{
+			{RTP: 0.9, Payment: 10, Profit: 9},
+			{RTP: 1.1, Payment: 10, Profit: 11},
+		},
+		CI: []interval{
+			{CL: 0.95, Mean: 1, Lo: 0.8, Hi: 1.2},
+		},
+	}
+}
+
+func testBankroll() *bankrollReport {
+	return &bankrollReport{
+		Ruin:    []interval{{CL: 0.95, Mean: 0.5, Lo: 0, Hi: 1}},
+		Length:  checker.Distribution{Mean: 5, Min: 1, P5: 1, P25: 2, P50: 5, P75: 8, P95: 9, Max: 9},
+		Balance: checker.Distribution{Mean: 50, Min: 0, P5: 0, P25: 10, P50: 50, P75: 90, P95: 100, Max: 100},
+	}
+}
+
+func testVerdict() *verdict {
+	return newVerdict(checker.Verdict{Expected: 0.95, CL: 0.95, RTP: 1, Lo: 0.8, Hi: 1.2, Passed: true})
+}
+
+func writeString(t *testing.T, r *report, format string) string {
+	t.Helper()
+	var buf strings.Builder
+	w := bufio.NewWriter(&buf)
+	be.Err(be.Require(t), writeReport(w, r, format), nil)
+	be.Err(be.Require(t), w.Flush(), nil)
+	return buf.String()
+}
+
+func TestWriteText(t *testing.T) {
+	one := testReport()
+	one.Players = one.Players[:1]
+
+	withBankroll := testReport()
+	withBankroll.Bankroll = testBankroll()
+	withBankroll.Verdict = testVerdict() // в text вердикт не входит, он в stderr
+
+	tests := []struct {
+		name string
+		r    *report
+		want string
+	}{
+		{"one player", one, "0.9\n"},
+		{"players", testReport(), "1 0.8 1.2 0.95\n"},
+		{"bankroll", withBankroll, "1 0.8 1.2 0.95\n" +
+			"ruin 0.5 0 1 0.95\n" +
+			"length 5 1 1 2 5 8 9 9\n" +
+			"balance 50 0 0 10 50 90 100 100\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			be.Equal(t, writeString(t, tt.r, formatText), tt.want)
+		})
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	full := testReport()
+	full.Bankroll = testBankroll()
+	full.Verdict = testVerdict()
+
+	empty := testReport()
+	empty.Players = []playerReport{{RTP: number(math.NaN())}}
+	empty.CI = []interval{{CL: 0.95, Mean: number(math.NaN()), Lo: number(math.NaN()), Hi: number(math.Inf(1))}}
+
+	tests := []struct {
+		name string
+		r    *report
+		want string
+	}{
+		{"players", testReport(), `{"config":{"n":"2","strategy":"fixed:2"},"count":10,"elapsed":0.5,"max_multiplier":7,` +
+			`"players":[{"rtp":0.9,"payment":10,"profit":9},{"rtp":1.1,"payment":10,"profit":11}],` +
+			`"ci":[{"cl":0.95,"mean":1,"lo":0.8,"hi":1.2}]}`},
+		{"bankroll and verdict", full, `{"config":{"n":"2","strategy":"fixed:2"},"count":10,"elapsed":0.5,"max_multiplier":7,` +
+			`"players":[{"rtp":0.9,"payment":10,"profit":9},{"rtp":1.1,"payment":10,"profit":11}],` +
+			`"ci":[{"cl":0.95,"mean":1,"lo":0.8,"hi":1.2}],` +
+			`"bankroll":{"ruin":[{"cl":0.95,"mean":0.5,"lo":0,"hi":1}],` +
+			`"length":{"mean":5,"min":1,"p5":1,"p25":2,"p50":5,"p75":8,"p95":9,"max":9},` +
+			`"balance":{"mean":50,"min":0,"p5":0,"p25":10,"p50":50,"p75":90,"p95":100,"max":100}},` +
+			`"verdict":{"expected":0.95,"cl":0.95,"tolerance":0,"rtp":1,"lo":0.8,"hi":1.2,"passed":true}}`},
+		{"NaN", empty, `{"config":{"n":"2","strategy":"fixed:2"},"count":10,"elapsed":0.5,"max_multiplier":7,` +
+			`"players":[{"rtp":null,"payment":0,"profit":0}],` +
+			`"ci":[{"cl":0.95,"mean":null,"lo":null,"hi":null}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := writeString(t, tt.r, formatJSON)
+			var compact bytes.Buffer
+			be.Err(be.Require(t), json.Compact(&compact, []byte(got)), nil)
+			be.Equal(t, compact.String(), tt.want)
+		})
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	full := testReport()
+	full.Bankroll = testBankroll()
+	full.Verdict = testVerdict()
+
+	tests := []struct {
+		name string
+		r    *report
+		want string
+	}{
+		{"players", testReport(), `key,value
+config.n,2
+config.strategy,fixed:2
+count,10
+elapsed,0.5
+max_multiplier,7
+ci.0.95.mean,1
+ci.0.95.lo,0.8
+ci.0.95.hi,1.2
+players.0.rtp,0.9
+players.0.payment,10
+players.0.profit,9
+players.1.rtp,1.1
+players.1.payment,10
+players.1.profit,11
+`},
+		{"bankroll and verdict", full, `key,value
+config.n,2
+config.strategy,fixed:2
+count,10
+elapsed,0.5
+max_multiplier,7
+ci.0.95.mean,1
+ci.0.95.lo,0.8
+ci.0.95.hi,1.2
+players.0.rtp,0.9
+players.0.payment,10
+players.0.profit,9
+players.1.rtp,1.1
+players.1.payment,10
+players.1.profit,11
+bankroll.ruin.0.95.mean,0.5
+bankroll.ruin.0.95.lo,0
+bankroll.ruin.0.95.hi,1
+bankroll.length.mean,5
+bankroll.length.min,1
+bankroll.length.p5,1
+bankroll.length.p25,2
+bankroll.length.p50,5
+bankroll.length.p75,8
+bankroll.length.p95,9
+bankroll.length.max,9
+bankroll.balance.mean,50
+bankroll.balance.min,0
+bankroll.balance.p5,0
+bankroll.balance.p25,10
+bankroll.balance.p50,50
+bankroll.balance.p75,90
+bankroll.balance.p95,100
+bankroll.balance.max,100
+verdict.expected,0.95
+verdict.cl,0.95
+verdict.tolerance,0
+verdict.rtp,1
+verdict.lo,0.8
+verdict.hi,1.2
+verdict.passed,true
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			be.Equal(t, writeString(t, tt.r, formatCSV), tt.want)
+		})
+	}
+}
@@ -3,13 +3,23 @@ module github.com/aaa2ppp/multgen
 go 1.24.2
 
 require (
+	github.com/BurntSushi/toml v1.6.0
 	github.com/aaa2ppp/be v0.0.0-20250921233015-47a341af14ef
+	github.com/fasthttp/websocket v1.5.12
 	github.com/valyala/fasthttp v1.66.0
 	gonum.org/v1/gonum v0.16.0
+	google.golang.org/grpc v1.76.0
+	google.golang.org/protobuf v1.36.6
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/andybalholm/brotli v1.2.0 // indirect
 	github.com/klauspost/compress v1.18.0 // indirect
+	github.com/savsgio/gotils v0.0.0-20240704082632-aef3928b8a38 // indirect
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	golang.org/x/net v0.44.0 // indirect
+	golang.org/x/sys v0.36.0 // indirect
+	golang.org/x/text v0.29.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250804133106-a7a43d27e69b // indirect
 )
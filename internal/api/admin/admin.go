@@ -0,0 +1,55 @@
+// Package admin — общая для net/http и fasthttp часть /admin/config:
+// проверка токена и смена конфигурации солвера на лету.
+package admin
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aaa2ppp/multgen/internal/solver"
+)
+
+// Reloader — солвер, конфигурацию которого можно сменить на лету (solver.Reloadable).
+type Reloader interface {
+	Config() solver.Config
+	Update(fn func(cfg *solver.Config) error) (solver.Config, error)
+}
+
+// Authorized проверяет заголовок Authorization: Bearer <token>.
+// Пустой token не пропускает никого.
+func Authorized(authorization []byte, token string) bool {
+	const prefix = "Bearer "
+	if token == "" || !bytes.HasPrefix(authorization, []byte(prefix)) {
+		return false
+	}
+	return subtle.ConstantTimeCompare(authorization[len(prefix):], []byte(token)) == 1
+}
+
+// UpdateConfig накладывает JSON body (поля solver.Config, например
+// {"rtp":0.95,"algorithm":"pareto1"}) на текущую конфигурацию и перезагружает
+// солвер. Незаданные поля сохраняют текущие значения, кроме seed: без него
+// выбирается новое случайное зерно. Неизвестный алгоритм — ошибка, остальное
+// проверяется в solver.New; при ошибке солвер не меняется.
+//
+// dist_file менять нельзя: иначе сервер читал бы по запросу любой файл.
+func UpdateConfig(r Reloader, body []byte) (solver.Config, error) {
+	return r.Update(func(cfg *solver.Config) error {
+		distFile := cfg.DistFile
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(cfg); err != nil {
+			return fmt.Errorf("bad config: %w", err)
+		}
+		if cfg.DistFile != distFile {
+			return errors.New("bad config: dist_file can't be changed on the fly")
+		}
+		// solver.New заменил бы неизвестный алгоритм на min
+		if _, ok := solver.Lookup(cfg.Algorithm); !ok {
+			return fmt.Errorf("bad config: unknown algorithm %q", cfg.Algorithm)
+		}
+		return nil
+	})
+}
@@ -36,3 +36,30 @@ var bufPool = &bufferPool{
 
 func Get() []byte  { return bufPool.Get() }
 func Put(b []byte) { bufPool.Put(b) }
+
+// MaxGrowableSize — буферы большей ёмкости в пул не возвращаются,
+// чтобы один большой пакетный ответ не держал память навсегда.
+const MaxGrowableSize = 1 << 20
+
+// Growable — буфер переменного размера для пакетных ответов. Растёт по мере
+// надобности и возвращается в пул вместе с выросшей ёмкостью.
+type Growable struct {
+	B []byte
+}
+
+var growablePool = sync.Pool{
+	New: func() any { return &Growable{B: make([]byte, 0, Size)} },
+}
+
+func GetGrowable() *Growable {
+	b := growablePool.Get().(*Growable)
+	b.B = b.B[:0]
+	return b
+}
+
+func PutGrowable(b *Growable) {
+	if cap(b.B) > MaxGrowableSize {
+		return
+	}
+	growablePool.Put(b)
+}
@@ -0,0 +1,54 @@
+package fastapi
+
+import (
+	"encoding/json"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/aaa2ppp/multgen/internal/api/admin"
+	"github.com/aaa2ppp/multgen/internal/solver"
+	"github.com/aaa2ppp/multgen/internal/stats"
+)
+
+// RequireToken пропускает только запросы с Authorization: Bearer <token>.
+func RequireToken(token string, h fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if !admin.Authorized(ctx.Request.Header.Peek("Authorization"), token) {
+			ctx.Response.Header.Set("WWW-Authenticate", "Bearer")
+			ctx.Error("Unauthorized", fasthttp.StatusUnauthorized)
+			return
+		}
+		h(ctx)
+	}
+}
+
+// GetConfigHandler отдаёт текущую конфигурацию солвера.
+func GetConfigHandler(rl admin.Reloader) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		writeConfig(ctx, rl.Config())
+	}
+}
+
+// PutConfigHandler меняет конфигурацию солвера на лету (см. admin.UpdateConfig).
+func PutConfigHandler(rl admin.Reloader, st *stats.Stats) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		cfg, err := admin.UpdateConfig(rl, ctx.PostBody())
+		if err != nil {
+			ctx.Error(err.Error(), fasthttp.StatusBadRequest)
+			return
+		}
+		st.SetMin(cfg.Min)
+
+		writeConfig(ctx, cfg)
+	}
+}
+
+func writeConfig(ctx *fasthttp.RequestCtx, cfg solver.Config) {
+	body, err := json.Marshal(cfg)
+	if err != nil {
+		ctx.Error(err.Error(), fasthttp.StatusInternalServerError)
+		return
+	}
+	ctx.SetContentType("application/json")
+	ctx.SetBody(body)
+}
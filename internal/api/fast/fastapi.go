@@ -2,44 +2,158 @@ package fastapi
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"math"
 	"strconv"
+	"sync"
+	"time"
 
+	"github.com/fasthttp/websocket"
 	"github.com/valyala/fasthttp"
 
+	"github.com/aaa2ppp/multgen/internal/api/admin"
 	"github.com/aaa2ppp/multgen/internal/api/buffer"
+	"github.com/aaa2ppp/multgen/internal/api/override"
+	"github.com/aaa2ppp/multgen/internal/api/ws"
+	"github.com/aaa2ppp/multgen/internal/format"
+	"github.com/aaa2ppp/multgen/internal/game"
+	"github.com/aaa2ppp/multgen/internal/metrics"
+	"github.com/aaa2ppp/multgen/internal/solver"
+	"github.com/aaa2ppp/multgen/internal/stats"
 )
 
 type Solver interface {
 	Solve() float64
+	SolvePrecision() (float64, int) // мультипликатор и его точность от одного солвера
+	Observe(bet float64, win bool, payout float64)
+	TheoreticalRTP(x float64) (float64, bool)
+	RoundStats() solver.RoundStats
 }
 
-func New(s Solver) func(ctx *fasthttp.RequestCtx) {
+type Options struct {
+	BatchLimit int              // максимальное n в /get?n=K (0 - game.DefaultBatchLimit)
+	Metrics    *metrics.Metrics // nil - без метрик и /metrics
+	Stats      *stats.Stats     // nil - без /stats
+	Games      map[string]Game  // профили солвера на /games/{id}/...
+	Overrides  *override.Cache  // nil - параметры /get?rtp=&algo=&alpha= игнорируются
+
+	// Смена конфигурации солвера на лету через /admin/config
+	// (только вместе с непустым AdminToken).
+	Reloader   admin.Reloader
+	AdminToken string
+
+	// Сессии /ws работают в соединениях, перехваченных у fasthttp, уже после
+	// выхода из обработчика. Context отменяется при остановке сервера и
+	// закрывает их (nil - не закрываются), в Sessions они учитываются, чтобы
+	// остановка могла их дождаться (nil - не учитываются).
+	Context  context.Context
+	Sessions *sync.WaitGroup
+}
+
+type route struct {
+	method  []byte
+	path    []byte
+	handler fasthttp.RequestHandler
+	metrics *metrics.Route
+}
+
+func New(s Solver, opts Options) func(ctx *fasthttp.RequestCtx) {
+	if opts.BatchLimit <= 0 {
+		opts.BatchLimit = game.DefaultBatchLimit
+	}
+
+	m := opts.Metrics
+	s = m.Instrument(s)
+	s = opts.Stats.Instrument(s)
+
+	var routes []route
+	handle := func(method, path string, h fasthttp.RequestHandler) {
+		routes = append(routes, route{[]byte(method), []byte(path), h, m.Route(path)})
+	}
+
+	handle("GET", "/get", OverrideHandler(opts.Overrides, s, opts.BatchLimit))
+	handle("POST", "/play", PlayHandler(s))
+	handle("GET", "/ws", WSHandler(opts.Context, opts.Sessions, s))
+	handle("GET", "/ping", PingHandler)
+	if m != nil {
+		handle("GET", "/metrics", MetricsHandler(m))
+	}
+	if opts.Stats != nil {
+		handle("GET", "/stats", StatsHandler(opts.Stats, s))
+	}
+	if opts.Reloader != nil && opts.AdminToken != "" {
+		handle("GET", "/admin/config", RequireToken(opts.AdminToken, GetConfigHandler(opts.Reloader)))
+		handle("PUT", "/admin/config", RequireToken(opts.AdminToken, PutConfigHandler(opts.Reloader, opts.Stats)))
+	}
+
+	games := gameRoutes(opts.Games, opts.BatchLimit, m)
+
 	return func(ctx *fasthttp.RequestCtx) {
-		if !ctx.IsGet() {
-			ctx.Error("Method Not Allowed", fasthttp.StatusMethodNotAllowed)
+		routes, path := routes, ctx.Path()
+		if id, rest, ok := splitGamePath(path); ok {
+			routes, path = games[string(id)], rest // неизвестный id - nil, 404
+		}
+
+		r, allowed := lookup(routes, ctx.Method(), path)
+		if r == nil {
+			ctx.Error("Not Found", fasthttp.StatusNotFound)
 			return
 		}
 
-		// No-cache
-		ctx.Response.Header.Set("Cache-Control", "no-cache, no-store, must-revalidate")
-		ctx.Response.Header.Set("Pragma", "no-cache")
-		ctx.Response.Header.Set("Expires", "0")
+		var start time.Time
+		if r.metrics != nil {
+			start = time.Now()
+		}
 
-		path := ctx.Path()
-		switch {
-		case bytes.Equal(path, []byte("/get")):
-			GetHandler(s)(ctx)
-		case bytes.Equal(path, []byte("/ping")):
-			PingHandler(ctx)
-		default:
-			ctx.Error("Not Found", fasthttp.StatusNotFound)
+		if !allowed {
+			ctx.Error("Method Not Allowed", fasthttp.StatusMethodNotAllowed)
+		} else {
+			// No-cache
+			ctx.Response.Header.Set("Cache-Control", "no-cache, no-store, must-revalidate")
+			ctx.Response.Header.Set("Pragma", "no-cache")
+			ctx.Response.Header.Set("Expires", "0")
+
+			r.handler(ctx)
+		}
+
+		if r.metrics != nil {
+			r.metrics.Observe(ctx.Response.StatusCode(), time.Since(start))
+		}
+	}
+}
+
+// lookup ищет маршрут path; allowed - метод тоже совпал.
+func lookup(routes []route, method, path []byte) (r *route, allowed bool) {
+	for i := range routes {
+		if !bytes.Equal(routes[i].path, path) {
+			continue
+		}
+		r = &routes[i]
+		if allowed = bytes.Equal(r.method, method); allowed {
+			break
+		}
+	}
+	return r, allowed
+}
+
+// getOrBatchHandler — /get: один мультипликатор или, с параметром n, пачка.
+func getOrBatchHandler(s Solver, limit int) fasthttp.RequestHandler {
+	getHandler := GetHandler(s)
+	batchHandler := BatchHandler(s, limit)
+
+	return func(ctx *fasthttp.RequestCtx) {
+		if ctx.QueryArgs().Has("n") {
+			batchHandler(ctx)
+		} else {
+			getHandler(ctx)
 		}
 	}
 }
 
 func GetHandler(s Solver) fasthttp.RequestHandler {
 	return func(ctx *fasthttp.RequestCtx) {
-		multiplier := s.Solve()
+		multiplier, prec := s.SolvePrecision()
 
 		// TODO: Can we avoid the buffer pool and write directly to fasthttp's response buffer?
 
@@ -47,7 +161,7 @@ func GetHandler(s Solver) fasthttp.RequestHandler {
 		buf := buffer.Get()
 
 		buf = append(buf, `{"result":`...)
-		buf = strconv.AppendFloat(buf, multiplier, 'g', -1, 64)
+		buf = format.AppendMultiplier(buf, multiplier, prec)
 		buf = append(buf, '}')
 
 		ctx.SetContentType("application/json")
@@ -58,6 +172,132 @@ func GetHandler(s Solver) fasthttp.RequestHandler {
 	}
 }
 
+// BatchHandler отвечает на /get?n=K массивом из K мультипликаторов: {"result":[m1,...]}.
+func BatchHandler(s Solver, limit int) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		n, err := ctx.QueryArgs().GetUint("n")
+		if err != nil || n < 1 || n > limit {
+			ctx.Error("n must be an integer in [1, "+strconv.Itoa(limit)+"]", fasthttp.StatusBadRequest)
+			return
+		}
+
+		b := buffer.GetGrowable()
+		buf := b.B
+
+		buf = append(buf, `{"result":[`...)
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			m, prec := s.SolvePrecision()
+			buf = format.AppendMultiplier(buf, m, prec)
+		}
+		buf = append(buf, "]}"...)
+
+		ctx.SetContentType("application/json")
+		ctx.SetBody(buf) // fasthttp делает copy
+
+		b.B = buf
+		buffer.PutGrowable(b)
+	}
+}
+
+// PlayHandler разыгрывает раунд на сервере: принимает {"bet":1,"target":2},
+// отвечает {"multiplier":m,"win":true,"payout":2} и сообщает итог солверу.
+func PlayHandler(s Solver) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		var req game.PlayRequest
+		if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
+			ctx.Error("bad request: "+err.Error(), fasthttp.StatusBadRequest)
+			return
+		}
+
+		bet := game.Bet{Bet: req.Bet, X: req.Target}
+		if err := bet.Validate(); err != nil {
+			ctx.Error(err.Error(), fasthttp.StatusBadRequest)
+			return
+		}
+
+		outcome := game.Play(s, bet)
+
+		b := buffer.GetGrowable()
+		buf := outcome.AppendJSON(b.B)
+
+		ctx.SetContentType("application/json")
+		ctx.SetBody(buf) // fasthttp делает copy
+
+		b.B = buf
+		buffer.PutGrowable(b)
+	}
+}
+
+var upgrader = websocket.FastHTTPUpgrader{}
+
+// WSHandler — протокол раундов поверх WebSocket (см. пакет ws). Сессия
+// завершается при отмене serverCtx (nil - не завершается) и учитывается в
+// sessions (nil - не учитывается).
+func WSHandler(serverCtx context.Context, sessions *sync.WaitGroup, s Solver) fasthttp.RequestHandler {
+	if serverCtx == nil {
+		serverCtx = context.Background()
+	}
+	if sessions == nil {
+		sessions = new(sync.WaitGroup)
+	}
+
+	return func(ctx *fasthttp.RequestCtx) {
+		// Add до выхода из обработчика: пока он работает, остановка ждёт
+		// запросы и не может раньше времени дождаться пустого sessions.
+		// Если fasthttp не смог отправить ответ 101, сессия не начнётся и
+		// остановка дождётся тайм-аута.
+		sessions.Add(1)
+		err := upgrader.Upgrade(ctx, func(conn *websocket.Conn) {
+			defer sessions.Done()
+			ws.Serve(serverCtx, conn, s)
+		})
+		if err != nil {
+			sessions.Done() // Upgrade уже ответил клиенту ошибкой
+		}
+	}
+}
+
+// MetricsHandler отдаёт метрики в текстовом формате Prometheus.
+func MetricsHandler(m *metrics.Metrics) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		b := buffer.GetGrowable()
+		buf := m.AppendText(b.B)
+
+		ctx.SetContentType("text/plain; version=0.0.4; charset=utf-8")
+		ctx.SetBody(buf) // fasthttp делает copy
+
+		b.B = buf
+		buffer.PutGrowable(b)
+	}
+}
+
+// StatsHandler отдаёт статистику выданных мультипликаторов с момента старта
+// и, если задан параметр x, теоретический RTP для x.
+func StatsHandler(st *stats.Stats, s Solver) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		var x float64
+		if ctx.QueryArgs().Has("x") {
+			var err error
+			if x, err = ctx.QueryArgs().GetUfloat("x"); err != nil || !(x > 0) || math.IsInf(x, 0) {
+				ctx.Error("x must be a finite number > 0", fasthttp.StatusBadRequest)
+				return
+			}
+		}
+
+		body, err := json.Marshal(st.Report(s, x))
+		if err != nil {
+			ctx.Error(err.Error(), fasthttp.StatusInternalServerError)
+			return
+		}
+
+		ctx.SetContentType("application/json")
+		ctx.SetBody(body)
+	}
+}
+
 func PingHandler(ctx *fasthttp.RequestCtx) {
 	ctx.SetBodyString("pong")
 }
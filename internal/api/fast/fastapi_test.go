@@ -1,14 +1,25 @@
 package fastapi_test
 
 import (
+	"context"
+	"net"
 	"net/http"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/aaa2ppp/be"
+	"github.com/fasthttp/websocket"
 	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
 
 	fastapi "github.com/aaa2ppp/multgen/internal/api/fast"
+	"github.com/aaa2ppp/multgen/internal/api/override"
+	"github.com/aaa2ppp/multgen/internal/game"
+	"github.com/aaa2ppp/multgen/internal/metrics"
 	"github.com/aaa2ppp/multgen/internal/solver"
+	"github.com/aaa2ppp/multgen/internal/stats"
 	"github.com/aaa2ppp/multgen/internal/testutils"
 )
 
@@ -46,3 +57,271 @@ func Benchmark_getHandler(b *testing.B) {
 		}
 	})
 }
+
+func TestBatchHandler(t *testing.T) {
+	s, err := solver.New(solver.DefaultConfig())
+	be.Err(t, err, nil)
+
+	handler := fastapi.New(s, fastapi.Options{BatchLimit: 3})
+
+	tests := []struct {
+		uri  string
+		code int
+		body string
+	}{
+		{"/get?n=3", http.StatusOK, `{"result":[1,1,1]}`},
+		{"/get?n=0", http.StatusBadRequest, ""},
+		{"/get?n=4", http.StatusBadRequest, ""},
+		{"/get?n=-1", http.StatusBadRequest, ""},
+		{"/get", http.StatusOK, `{"result":1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.SetRequestURI(tt.uri)
+
+			handler(ctx)
+
+			be.Equal(be.Require(t), ctx.Response.StatusCode(), tt.code)
+			if tt.code == http.StatusOK {
+				be.Equal(t, string(ctx.Response.Body()), tt.body)
+			}
+		})
+	}
+}
+
+func TestWSHandler(t *testing.T) {
+	cfg := solver.DefaultConfig()
+	cfg.Algorithm = "max"
+	s, err := solver.New(cfg)
+	be.Err(be.Require(t), err, nil)
+
+	ln := fasthttputil.NewInmemoryListener()
+	defer ln.Close()
+	go fasthttp.Serve(ln, fastapi.New(s, fastapi.Options{}))
+
+	dialer := websocket.Dialer{NetDial: func(string, string) (net.Conn, error) { return ln.Dial() }}
+	conn, _, err := dialer.Dial("ws://test/ws", nil)
+	be.Err(be.Require(t), err, nil)
+	defer conn.Close()
+
+	be.Err(be.Require(t), conn.WriteJSON(game.Bet{Bet: 2, X: 1.5}), nil)
+	var res game.Result
+	be.Err(be.Require(t), conn.ReadJSON(&res), nil)
+	be.Equal(t, res, game.Result{Round: 1, Outcome: game.Outcome{Multiplier: 10000, Win: true, Payout: 3}, RTP: 1.5})
+}
+
+func TestWSHandler_Shutdown(t *testing.T) {
+	s, err := solver.New(solver.DefaultConfig())
+	be.Err(be.Require(t), err, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var sessions sync.WaitGroup
+
+	ln := fasthttputil.NewInmemoryListener()
+	defer ln.Close()
+	go fasthttp.Serve(ln, fastapi.New(s, fastapi.Options{Context: ctx, Sessions: &sessions}))
+
+	dialer := websocket.Dialer{NetDial: func(string, string) (net.Conn, error) { return ln.Dial() }}
+	conn, _, err := dialer.Dial("ws://test/ws", nil)
+	be.Err(be.Require(t), err, nil)
+	defer conn.Close()
+
+	// остановка сервера закрывает сессию, и её можно дождаться
+	cancel()
+	_, _, err = conn.ReadMessage()
+	be.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway))
+
+	finished := make(chan struct{})
+	go func() {
+		sessions.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("session is not finished")
+	}
+}
+
+func TestPlayHandler(t *testing.T) {
+	cfg := solver.DefaultConfig()
+	cfg.Algorithm = "max"
+	s, err := solver.New(cfg)
+	be.Err(be.Require(t), err, nil)
+
+	handler := fastapi.New(s, fastapi.Options{})
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod(http.MethodPost)
+	ctx.Request.SetRequestURI("/play")
+	ctx.Request.SetBodyString(`{"bet":2,"target":1.5}`)
+	handler(ctx)
+
+	be.Equal(be.Require(t), ctx.Response.StatusCode(), http.StatusOK)
+	be.Equal(t, string(ctx.Response.Body()), `{"multiplier":10000,"win":true,"payout":3}`)
+	be.Equal(t, s.RoundStats().RTP(), 1.5)
+
+	ctx = &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/play")
+	handler(ctx)
+	be.Equal(t, ctx.Response.StatusCode(), http.StatusMethodNotAllowed)
+}
+
+func TestOverride(t *testing.T) {
+	s, err := solver.New(solver.DefaultConfig())
+	be.Err(be.Require(t), err, nil)
+	r := solver.NewReloadable(s)
+	cache := override.NewCache(override.Policy{Algorithms: override.List{"max"}}, r.Config)
+	handler := fastapi.New(r, fastapi.Options{Overrides: cache})
+
+	tests := []struct {
+		uri  string
+		code int
+		want string
+	}{
+		{"/get", http.StatusOK, `{"result":1}`},
+		{"/get?algo=max", http.StatusOK, `{"result":10000}`},
+		{"/get?algo=max&n=2", http.StatusOK, `{"result":[10000,10000]}`},
+		{"/get?algo=crash", http.StatusBadRequest, ""},
+		{"/get?rtp=0.9", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.SetRequestURI(tt.uri)
+			handler(ctx)
+
+			be.Equal(be.Require(t), ctx.Response.StatusCode(), tt.code)
+			if tt.want != "" {
+				be.Equal(t, string(ctx.Response.Body()), tt.want)
+			}
+		})
+	}
+	be.Equal(t, cache.Len(), 1)
+}
+
+func TestGames(t *testing.T) {
+	s, err := solver.New(solver.DefaultConfig())
+	be.Err(be.Require(t), err, nil)
+
+	cfg := solver.DefaultConfig()
+	cfg.Algorithm = "max"
+	maxSolver, err := solver.New(cfg)
+	be.Err(be.Require(t), err, nil)
+	minSolver, err := solver.New(solver.DefaultConfig())
+	be.Err(be.Require(t), err, nil)
+
+	handler := fastapi.New(s, fastapi.Options{Games: map[string]fastapi.Game{
+		"max": {Solver: maxSolver, Stats: stats.New(cfg.Min)},
+		"min": {Solver: minSolver},
+	}})
+
+	tests := []struct {
+		method string
+		uri    string
+		body   string
+		code   int
+		want   string
+	}{
+		{http.MethodGet, "/games/max/get", "", http.StatusOK, `{"result":10000}`},
+		{http.MethodGet, "/games/min/get?n=2", "", http.StatusOK, `{"result":[1,1]}`},
+		{http.MethodPost, "/games/max/play", `{"bet":2,"target":1.5}`, http.StatusOK, `{"multiplier":10000,"win":true,"payout":3}`},
+		{http.MethodGet, "/games/max/stats", "", http.StatusOK, ""},
+		{http.MethodGet, "/games/min/stats", "", http.StatusNotFound, ""},
+		{http.MethodGet, "/games/nope/get", "", http.StatusNotFound, ""},
+		{http.MethodGet, "/games/max", "", http.StatusNotFound, ""},
+		{http.MethodGet, "/games/max/play", "", http.StatusMethodNotAllowed, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.uri, func(t *testing.T) {
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.Header.SetMethod(tt.method)
+			ctx.Request.SetRequestURI(tt.uri)
+			ctx.Request.SetBodyString(tt.body)
+			handler(ctx)
+
+			be.Equal(be.Require(t), ctx.Response.StatusCode(), tt.code)
+			if tt.want != "" {
+				be.Equal(t, string(ctx.Response.Body()), tt.want)
+			}
+		})
+	}
+
+	// у каждого профиля своя статистика, основной солвер не задет
+	be.Equal(t, maxSolver.RoundStats().Rounds, uint64(1))
+	be.Equal(t, s.RoundStats().Rounds, uint64(0))
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/games/max/stats")
+	handler(ctx)
+	be.True(t, strings.Contains(string(ctx.Response.Body()), `"rounds":{"count":1,"wins":1,"bet":2,"payout":3,"rtp":1.5}`))
+}
+
+func TestMetrics(t *testing.T) {
+	cfg := solver.DefaultConfig()
+	cfg.Algorithm = "max"
+	s, err := solver.New(cfg)
+	be.Err(be.Require(t), err, nil)
+
+	handler := fastapi.New(s, fastapi.Options{Metrics: metrics.New()})
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/get")
+
+	// горячий путь с метриками не аллоцирует
+	allocs := testing.AllocsPerRun(1000, func() { handler(ctx) })
+	if !raceEnabled {
+		be.Equal(t, allocs, 0.0)
+	}
+
+	ctx.Request.SetRequestURI("/ping")
+	ctx.Request.Header.SetMethod(http.MethodPost)
+	handler(ctx)
+
+	ctx = &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/metrics")
+	handler(ctx)
+
+	be.Equal(be.Require(t), ctx.Response.StatusCode(), http.StatusOK)
+	body := string(ctx.Response.Body())
+	for _, want := range []string{
+		`multgen_http_requests_total{route="/get",code="200"} 1001` + "\n", // AllocsPerRun делает лишний прогон
+		`multgen_http_requests_total{route="/ping",code="405"} 1` + "\n",
+		`multgen_multiplier_bucket{le="1000"} 0` + "\n",
+		`multgen_multiplier_bucket{le="10000"} 1001` + "\n",
+		`multgen_http_request_duration_seconds_count{route="/get"} 1001` + "\n",
+	} {
+		be.True(t, strings.Contains(body, want))
+	}
+	be.True(t, !strings.Contains(body, "multgen_realized_rtp"))
+}
+
+func TestAdminConfig(t *testing.T) {
+	s, err := solver.New(solver.DefaultConfig())
+	be.Err(be.Require(t), err, nil)
+	rs := solver.NewReloadable(s)
+	handler := fastapi.New(rs, fastapi.Options{Reloader: rs, AdminToken: "secret"})
+
+	do := func(method, uri, token, body string) *fasthttp.RequestCtx {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.Header.SetMethod(method)
+		ctx.Request.SetRequestURI(uri)
+		if token != "" {
+			ctx.Request.Header.Set("Authorization", "Bearer "+token)
+		}
+		ctx.Request.SetBodyString(body)
+		handler(ctx)
+		return ctx
+	}
+
+	be.Equal(t, do(http.MethodPut, "/admin/config", "wrong", `{"algorithm":"max"}`).Response.StatusCode(), http.StatusUnauthorized)
+	be.Equal(t, do(http.MethodPost, "/admin/config", "secret", `{"algorithm":"max"}`).Response.StatusCode(), http.StatusMethodNotAllowed)
+	be.Equal(t, do(http.MethodPut, "/admin/config", "secret", `{"algorithm":"typo"}`).Response.StatusCode(), http.StatusBadRequest)
+	be.Equal(t, do(http.MethodPut, "/admin/config", "secret", `{"algorithm":"max"}`).Response.StatusCode(), http.StatusOK)
+	be.Equal(t, string(do(http.MethodGet, "/get", "", "").Response.Body()), `{"result":10000}`)
+}
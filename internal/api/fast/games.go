@@ -0,0 +1,60 @@
+package fastapi
+
+import (
+	"bytes"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/aaa2ppp/multgen/internal/metrics"
+	"github.com/aaa2ppp/multgen/internal/stats"
+)
+
+// Game — именованный профиль солвера, обслуживается на /games/{id}/...
+type Game struct {
+	Solver Solver
+	Stats  *stats.Stats // nil - без /games/{id}/stats
+}
+
+var gamesPrefix = []byte("/games/")
+
+// gameRoutes строит таблицы маршрутов профилей: пути в них — без
+// префикса /games/{id}. Телеметрия мультипликаторов и раундов (/metrics) —
+// только по основному солверу, у профилей своя /stats.
+func gameRoutes(games map[string]Game, batchLimit int, m *metrics.Metrics) map[string][]route {
+	if len(games) == 0 {
+		return nil
+	}
+
+	tables := make(map[string][]route, len(games))
+	for id, g := range games {
+		s := g.Stats.Instrument(g.Solver)
+
+		var routes []route
+		handle := func(method, path string, h fasthttp.RequestHandler) {
+			routes = append(routes, route{[]byte(method), []byte(path), h, m.Route("/games/{id}" + path)})
+		}
+
+		handle("GET", "/get", getOrBatchHandler(s, batchLimit))
+		handle("POST", "/play", PlayHandler(s))
+		if g.Stats != nil {
+			handle("GET", "/stats", StatsHandler(g.Stats, s))
+		}
+
+		tables[id] = routes
+	}
+
+	return tables
+}
+
+// splitGamePath разбирает /games/{id}/rest на id и /rest.
+func splitGamePath(path []byte) (id, rest []byte, ok bool) {
+	tail, ok := bytes.CutPrefix(path, gamesPrefix)
+	if !ok {
+		return nil, nil, false
+	}
+	i := bytes.IndexByte(tail, '/')
+	if i <= 0 {
+		return nil, nil, false
+	}
+	return tail[:i], tail[i:], true
+}
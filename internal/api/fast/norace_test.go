@@ -0,0 +1,5 @@
+//go:build !race
+
+package fastapi_test
+
+const raceEnabled = false
@@ -0,0 +1,43 @@
+package fastapi
+
+import (
+	"github.com/valyala/fasthttp"
+
+	"github.com/aaa2ppp/multgen/internal/api/override"
+)
+
+// OverrideHandler — /get с параметрами rtp, algo, alpha: мультипликаторы
+// выдаёт солвер из c для этой конфигурации (без телеметрии и /stats основного
+// солвера). Без параметров или при nil c — обычный /get.
+func OverrideHandler(c *override.Cache, s Solver, limit int) fasthttp.RequestHandler {
+	get := getOrBatchHandler(s, limit)
+	if c == nil {
+		return get
+	}
+
+	return func(ctx *fasthttp.RequestCtx) {
+		args := ctx.QueryArgs()
+		if args.Len() == 0 {
+			get(ctx)
+			return
+		}
+
+		params, ok, err := override.ParseParams(func(name string) string { return string(args.Peek(name)) })
+		if err != nil {
+			ctx.Error(err.Error(), fasthttp.StatusBadRequest)
+			return
+		}
+		if !ok {
+			get(ctx)
+			return
+		}
+
+		custom, err := c.Solver(params)
+		if err != nil {
+			ctx.Error(err.Error(), fasthttp.StatusBadRequest)
+			return
+		}
+
+		getOrBatchHandler(custom, limit)(ctx)
+	}
+}
@@ -0,0 +1,7 @@
+//go:build race
+
+package fastapi_test
+
+// raceEnabled — тесты собраны с -race: детектор гонок аллоцирует сам,
+// проверки AllocsPerRun не имеют смысла.
+const raceEnabled = true
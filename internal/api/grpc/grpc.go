@@ -0,0 +1,98 @@
+// Package grpcapi — gRPC-сервис мультипликаторов (multgenpb.Multgen) с теми же
+// операциями, что у HTTP API: Get (/get), Stream (/stream) и Play (/play).
+package grpcapi
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative multgenpb/multgen.proto
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/aaa2ppp/multgen/internal/api/grpc/multgenpb"
+	"github.com/aaa2ppp/multgen/internal/game"
+)
+
+type Solver interface {
+	game.Solver
+	Solve() float64
+}
+
+type Options struct {
+	StreamInterval time.Duration // интервал между сообщениями Stream по умолчанию (0 - game.DefaultStreamInterval)
+
+	// Закрытие Shutdown завершает открытые Stream, иначе они не дадут
+	// серверу остановиться (GracefulStop ждёт все вызовы).
+	Shutdown <-chan struct{}
+}
+
+type server struct {
+	multgenpb.UnimplementedMultgenServer
+
+	s              Solver
+	streamInterval time.Duration
+	shutdown       <-chan struct{}
+}
+
+// New возвращает реализацию сервиса для multgenpb.RegisterMultgenServer.
+func New(s Solver, opts Options) multgenpb.MultgenServer {
+	if opts.StreamInterval <= 0 {
+		opts.StreamInterval = game.DefaultStreamInterval
+	}
+	return &server{s: s, streamInterval: opts.StreamInterval, shutdown: opts.Shutdown}
+}
+
+func (srv *server) Get(context.Context, *multgenpb.GetRequest) (*multgenpb.GetResponse, error) {
+	return &multgenpb.GetResponse{Result: srv.s.Solve()}, nil
+}
+
+// Stream шлёт мультипликаторы по одному за интервал (по умолчанию
+// Options.StreamInterval, не меньше game.MinStreamInterval), пока клиент не
+// отменит вызов, не будет отправлено n сообщений (n = 0 - без ограничения)
+// или не начнётся остановка сервера.
+func (srv *server) Stream(req *multgenpb.StreamRequest, stream multgenpb.Multgen_StreamServer) error {
+	interval := srv.streamInterval
+	if req.Interval != nil {
+		if err := req.Interval.CheckValid(); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		if interval = req.Interval.AsDuration(); interval < game.MinStreamInterval {
+			return status.Error(codes.InvalidArgument, "interval must be >= "+game.MinStreamInterval.String())
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	ctx := stream.Context()
+	for i := uint64(1); ; i++ {
+		if err := stream.Send(&multgenpb.GetResponse{Result: srv.s.Solve()}); err != nil {
+			return err
+		}
+
+		if req.N != 0 && i == req.N {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-srv.shutdown:
+			return status.Error(codes.Unavailable, "server shutdown")
+		case <-ticker.C:
+		}
+	}
+}
+
+// Play разыгрывает раунд на сервере и сообщает итог солверу (см. game.Play).
+func (srv *server) Play(_ context.Context, req *multgenpb.PlayRequest) (*multgenpb.PlayResponse, error) {
+	bet := game.Bet{Bet: req.Bet, X: req.Target}
+	if err := bet.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	o := game.Play(srv.s, bet)
+	return &multgenpb.PlayResponse{Multiplier: o.Multiplier, Win: o.Win, Payout: o.Payout}, nil
+}
@@ -0,0 +1,106 @@
+package grpcapi_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/aaa2ppp/be"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	grpcapi "github.com/aaa2ppp/multgen/internal/api/grpc"
+	"github.com/aaa2ppp/multgen/internal/api/grpc/multgenpb"
+	"github.com/aaa2ppp/multgen/internal/game"
+	"github.com/aaa2ppp/multgen/internal/solver"
+)
+
+func newClient(t *testing.T, s grpcapi.Solver, opts grpcapi.Options) multgenpb.MultgenClient {
+	t.Helper()
+
+	ln := bufconn.Listen(1 << 16)
+	server := grpc.NewServer()
+	multgenpb.RegisterMultgenServer(server, grpcapi.New(s, opts))
+	go server.Serve(ln)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	be.Err(be.Require(t), err, nil)
+	t.Cleanup(func() { conn.Close() })
+
+	return multgenpb.NewMultgenClient(conn)
+}
+
+func TestGet(t *testing.T) {
+	s, err := solver.New(solver.DefaultConfig())
+	be.Err(be.Require(t), err, nil)
+	client := newClient(t, s, grpcapi.Options{})
+
+	resp, err := client.Get(context.Background(), &multgenpb.GetRequest{})
+	be.Err(be.Require(t), err, nil)
+	be.Equal(t, resp.Result, 1.0)
+}
+
+func TestStream(t *testing.T) {
+	s, err := solver.New(solver.DefaultConfig())
+	be.Err(be.Require(t), err, nil)
+	shutdown := make(chan struct{})
+	client := newClient(t, s, grpcapi.Options{Shutdown: shutdown})
+	ctx := context.Background()
+
+	stream, err := client.Stream(ctx, &multgenpb.StreamRequest{N: 3, Interval: durationpb.New(game.MinStreamInterval)})
+	be.Err(be.Require(t), err, nil)
+	var got []float64
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		be.Err(be.Require(t), err, nil)
+		got = append(got, resp.Result)
+	}
+	be.Equal(t, got, []float64{1, 1, 1})
+
+	stream, err = client.Stream(ctx, &multgenpb.StreamRequest{Interval: durationpb.New(time.Millisecond)})
+	be.Err(be.Require(t), err, nil)
+	_, err = stream.Recv()
+	be.Equal(t, status.Code(err), codes.InvalidArgument)
+
+	// остановка сервера завершает бесконечный поток
+	stream, err = client.Stream(ctx, &multgenpb.StreamRequest{Interval: durationpb.New(time.Hour)})
+	be.Err(be.Require(t), err, nil)
+	_, err = stream.Recv()
+	be.Err(be.Require(t), err, nil)
+	close(shutdown)
+	_, err = stream.Recv()
+	be.Equal(t, status.Code(err), codes.Unavailable)
+}
+
+func TestPlay(t *testing.T) {
+	cfg := solver.DefaultConfig()
+	cfg.Algorithm = "max"
+	s, err := solver.New(cfg)
+	be.Err(be.Require(t), err, nil)
+	client := newClient(t, s, grpcapi.Options{})
+	ctx := context.Background()
+
+	resp, err := client.Play(ctx, &multgenpb.PlayRequest{Bet: 2, Target: 1.5})
+	be.Err(be.Require(t), err, nil)
+	be.Equal(t, resp.Multiplier, 10000.0)
+	be.True(t, resp.Win)
+	be.Equal(t, resp.Payout, 3.0)
+
+	_, err = client.Play(ctx, &multgenpb.PlayRequest{Bet: 2, Target: 0})
+	be.Equal(t, status.Code(err), codes.InvalidArgument)
+
+	be.Equal(t, s.RoundStats(), solver.RoundStats{Rounds: 1, Wins: 1, Bet: 2, Payout: 3})
+}
@@ -0,0 +1,359 @@
+// Сервис мультипликаторов для gRPC-режима multgen (-grpc).
+// Код генерируется go generate в internal/api/grpc.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: multgenpb/multgen.proto
+
+package multgenpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRequest) Reset() {
+	*x = GetRequest{}
+	mi := &file_multgenpb_multgen_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRequest) ProtoMessage() {}
+
+func (x *GetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_multgenpb_multgen_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRequest.ProtoReflect.Descriptor instead.
+func (*GetRequest) Descriptor() ([]byte, []int) {
+	return file_multgenpb_multgen_proto_rawDescGZIP(), []int{0}
+}
+
+type GetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Result        float64                `protobuf:"fixed64,1,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetResponse) Reset() {
+	*x = GetResponse{}
+	mi := &file_multgenpb_multgen_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetResponse) ProtoMessage() {}
+
+func (x *GetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_multgenpb_multgen_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetResponse.ProtoReflect.Descriptor instead.
+func (*GetResponse) Descriptor() ([]byte, []int) {
+	return file_multgenpb_multgen_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetResponse) GetResult() float64 {
+	if x != nil {
+		return x.Result
+	}
+	return 0
+}
+
+type StreamRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Число мультипликаторов, после которого поток закрывается (0 - без ограничения).
+	N uint64 `protobuf:"varint,1,opt,name=n,proto3" json:"n,omitempty"`
+	// Интервал между мультипликаторами (не задан - по умолчанию сервера).
+	Interval      *durationpb.Duration `protobuf:"bytes,2,opt,name=interval,proto3" json:"interval,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamRequest) Reset() {
+	*x = StreamRequest{}
+	mi := &file_multgenpb_multgen_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamRequest) ProtoMessage() {}
+
+func (x *StreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_multgenpb_multgen_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamRequest.ProtoReflect.Descriptor instead.
+func (*StreamRequest) Descriptor() ([]byte, []int) {
+	return file_multgenpb_multgen_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *StreamRequest) GetN() uint64 {
+	if x != nil {
+		return x.N
+	}
+	return 0
+}
+
+func (x *StreamRequest) GetInterval() *durationpb.Duration {
+	if x != nil {
+		return x.Interval
+	}
+	return nil
+}
+
+type PlayRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bet           float64                `protobuf:"fixed64,1,opt,name=bet,proto3" json:"bet,omitempty"`
+	Target        float64                `protobuf:"fixed64,2,opt,name=target,proto3" json:"target,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlayRequest) Reset() {
+	*x = PlayRequest{}
+	mi := &file_multgenpb_multgen_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlayRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlayRequest) ProtoMessage() {}
+
+func (x *PlayRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_multgenpb_multgen_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlayRequest.ProtoReflect.Descriptor instead.
+func (*PlayRequest) Descriptor() ([]byte, []int) {
+	return file_multgenpb_multgen_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PlayRequest) GetBet() float64 {
+	if x != nil {
+		return x.Bet
+	}
+	return 0
+}
+
+func (x *PlayRequest) GetTarget() float64 {
+	if x != nil {
+		return x.Target
+	}
+	return 0
+}
+
+type PlayResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Multiplier    float64                `protobuf:"fixed64,1,opt,name=multiplier,proto3" json:"multiplier,omitempty"`
+	Win           bool                   `protobuf:"varint,2,opt,name=win,proto3" json:"win,omitempty"`
+	Payout        float64                `protobuf:"fixed64,3,opt,name=payout,proto3" json:"payout,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlayResponse) Reset() {
+	*x = PlayResponse{}
+	mi := &file_multgenpb_multgen_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlayResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlayResponse) ProtoMessage() {}
+
+func (x *PlayResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_multgenpb_multgen_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlayResponse.ProtoReflect.Descriptor instead.
+func (*PlayResponse) Descriptor() ([]byte, []int) {
+	return file_multgenpb_multgen_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *PlayResponse) GetMultiplier() float64 {
+	if x != nil {
+		return x.Multiplier
+	}
+	return 0
+}
+
+func (x *PlayResponse) GetWin() bool {
+	if x != nil {
+		return x.Win
+	}
+	return false
+}
+
+func (x *PlayResponse) GetPayout() float64 {
+	if x != nil {
+		return x.Payout
+	}
+	return 0
+}
+
+var File_multgenpb_multgen_proto protoreflect.FileDescriptor
+
+const file_multgenpb_multgen_proto_rawDesc = "" +
+	"\n" +
+	"\x17multgenpb/multgen.proto\x12\n" +
+	"multgen.v1\x1a\x1egoogle/protobuf/duration.proto\"\f\n" +
+	"\n" +
+	"GetRequest\"%\n" +
+	"\vGetResponse\x12\x16\n" +
+	"\x06result\x18\x01 \x01(\x01R\x06result\"T\n" +
+	"\rStreamRequest\x12\f\n" +
+	"\x01n\x18\x01 \x01(\x04R\x01n\x125\n" +
+	"\binterval\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\binterval\"7\n" +
+	"\vPlayRequest\x12\x10\n" +
+	"\x03bet\x18\x01 \x01(\x01R\x03bet\x12\x16\n" +
+	"\x06target\x18\x02 \x01(\x01R\x06target\"X\n" +
+	"\fPlayResponse\x12\x1e\n" +
+	"\n" +
+	"multiplier\x18\x01 \x01(\x01R\n" +
+	"multiplier\x12\x10\n" +
+	"\x03win\x18\x02 \x01(\bR\x03win\x12\x16\n" +
+	"\x06payout\x18\x03 \x01(\x01R\x06payout2\xbc\x01\n" +
+	"\aMultgen\x126\n" +
+	"\x03Get\x12\x16.multgen.v1.GetRequest\x1a\x17.multgen.v1.GetResponse\x12>\n" +
+	"\x06Stream\x12\x19.multgen.v1.StreamRequest\x1a\x17.multgen.v1.GetResponse0\x01\x129\n" +
+	"\x04Play\x12\x17.multgen.v1.PlayRequest\x1a\x18.multgen.v1.PlayResponseB8Z6github.com/aaa2ppp/multgen/internal/api/grpc/multgenpbb\x06proto3"
+
+var (
+	file_multgenpb_multgen_proto_rawDescOnce sync.Once
+	file_multgenpb_multgen_proto_rawDescData []byte
+)
+
+func file_multgenpb_multgen_proto_rawDescGZIP() []byte {
+	file_multgenpb_multgen_proto_rawDescOnce.Do(func() {
+		file_multgenpb_multgen_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_multgenpb_multgen_proto_rawDesc), len(file_multgenpb_multgen_proto_rawDesc)))
+	})
+	return file_multgenpb_multgen_proto_rawDescData
+}
+
+var file_multgenpb_multgen_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_multgenpb_multgen_proto_goTypes = []any{
+	(*GetRequest)(nil),          // 0: multgen.v1.GetRequest
+	(*GetResponse)(nil),         // 1: multgen.v1.GetResponse
+	(*StreamRequest)(nil),       // 2: multgen.v1.StreamRequest
+	(*PlayRequest)(nil),         // 3: multgen.v1.PlayRequest
+	(*PlayResponse)(nil),        // 4: multgen.v1.PlayResponse
+	(*durationpb.Duration)(nil), // 5: google.protobuf.Duration
+}
+var file_multgenpb_multgen_proto_depIdxs = []int32{
+	5, // 0: multgen.v1.StreamRequest.interval:type_name -> google.protobuf.Duration
+	0, // 1: multgen.v1.Multgen.Get:input_type -> multgen.v1.GetRequest
+	2, // 2: multgen.v1.Multgen.Stream:input_type -> multgen.v1.StreamRequest
+	3, // 3: multgen.v1.Multgen.Play:input_type -> multgen.v1.PlayRequest
+	1, // 4: multgen.v1.Multgen.Get:output_type -> multgen.v1.GetResponse
+	1, // 5: multgen.v1.Multgen.Stream:output_type -> multgen.v1.GetResponse
+	4, // 6: multgen.v1.Multgen.Play:output_type -> multgen.v1.PlayResponse
+	4, // [4:7] is the sub-list for method output_type
+	1, // [1:4] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_multgenpb_multgen_proto_init() }
+func file_multgenpb_multgen_proto_init() {
+	if File_multgenpb_multgen_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_multgenpb_multgen_proto_rawDesc), len(file_multgenpb_multgen_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_multgenpb_multgen_proto_goTypes,
+		DependencyIndexes: file_multgenpb_multgen_proto_depIdxs,
+		MessageInfos:      file_multgenpb_multgen_proto_msgTypes,
+	}.Build()
+	File_multgenpb_multgen_proto = out.File
+	file_multgenpb_multgen_proto_goTypes = nil
+	file_multgenpb_multgen_proto_depIdxs = nil
+}
@@ -0,0 +1,212 @@
+// Сервис мультипликаторов для gRPC-режима multgen (-grpc).
+// Код генерируется go generate в internal/api/grpc.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: multgenpb/multgen.proto
+
+package multgenpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Multgen_Get_FullMethodName    = "/multgen.v1.Multgen/Get"
+	Multgen_Stream_FullMethodName = "/multgen.v1.Multgen/Stream"
+	Multgen_Play_FullMethodName   = "/multgen.v1.Multgen/Play"
+)
+
+// MultgenClient is the client API for Multgen service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type MultgenClient interface {
+	// Get — один мультипликатор (как GET /get).
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error)
+	// Stream — поток мультипликаторов, по одному за интервал (как GET /stream).
+	Stream(ctx context.Context, in *StreamRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[GetResponse], error)
+	// Play — раунд игры на сервере (как POST /play): при multiplier > target
+	// выплата bet*target, иначе ставка проиграна.
+	Play(ctx context.Context, in *PlayRequest, opts ...grpc.CallOption) (*PlayResponse, error)
+}
+
+type multgenClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMultgenClient(cc grpc.ClientConnInterface) MultgenClient {
+	return &multgenClient{cc}
+}
+
+func (c *multgenClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetResponse)
+	err := c.cc.Invoke(ctx, Multgen_Get_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *multgenClient) Stream(ctx context.Context, in *StreamRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[GetResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Multgen_ServiceDesc.Streams[0], Multgen_Stream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamRequest, GetResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Multgen_StreamClient = grpc.ServerStreamingClient[GetResponse]
+
+func (c *multgenClient) Play(ctx context.Context, in *PlayRequest, opts ...grpc.CallOption) (*PlayResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PlayResponse)
+	err := c.cc.Invoke(ctx, Multgen_Play_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MultgenServer is the server API for Multgen service.
+// All implementations must embed UnimplementedMultgenServer
+// for forward compatibility.
+type MultgenServer interface {
+	// Get — один мультипликатор (как GET /get).
+	Get(context.Context, *GetRequest) (*GetResponse, error)
+	// Stream — поток мультипликаторов, по одному за интервал (как GET /stream).
+	Stream(*StreamRequest, grpc.ServerStreamingServer[GetResponse]) error
+	// Play — раунд игры на сервере (как POST /play): при multiplier > target
+	// выплата bet*target, иначе ставка проиграна.
+	Play(context.Context, *PlayRequest) (*PlayResponse, error)
+	mustEmbedUnimplementedMultgenServer()
+}
+
+// UnimplementedMultgenServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedMultgenServer struct{}
+
+func (UnimplementedMultgenServer) Get(context.Context, *GetRequest) (*GetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedMultgenServer) Stream(*StreamRequest, grpc.ServerStreamingServer[GetResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method Stream not implemented")
+}
+func (UnimplementedMultgenServer) Play(context.Context, *PlayRequest) (*PlayResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Play not implemented")
+}
+func (UnimplementedMultgenServer) mustEmbedUnimplementedMultgenServer() {}
+func (UnimplementedMultgenServer) testEmbeddedByValue()                 {}
+
+// UnsafeMultgenServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MultgenServer will
+// result in compilation errors.
+type UnsafeMultgenServer interface {
+	mustEmbedUnimplementedMultgenServer()
+}
+
+func RegisterMultgenServer(s grpc.ServiceRegistrar, srv MultgenServer) {
+	// If the following call pancis, it indicates UnimplementedMultgenServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Multgen_ServiceDesc, srv)
+}
+
+func _Multgen_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MultgenServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Multgen_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MultgenServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Multgen_Stream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MultgenServer).Stream(m, &grpc.GenericServerStream[StreamRequest, GetResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Multgen_StreamServer = grpc.ServerStreamingServer[GetResponse]
+
+func _Multgen_Play_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlayRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MultgenServer).Play(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Multgen_Play_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MultgenServer).Play(ctx, req.(*PlayRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Multgen_ServiceDesc is the grpc.ServiceDesc for Multgen service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Multgen_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "multgen.v1.Multgen",
+	HandlerType: (*MultgenServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Get",
+			Handler:    _Multgen_Get_Handler,
+		},
+		{
+			MethodName: "Play",
+			Handler:    _Multgen_Play_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Stream",
+			Handler:       _Multgen_Stream_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "multgenpb/multgen.proto",
+}
@@ -0,0 +1,247 @@
+// Package override — общая для net/http и fasthttp часть параметров /get,
+// меняющих конфигурацию солвера на один запрос: /get?rtp=0.95&algo=crash&alpha=2.
+//
+// Допустимые значения ограничивает Policy из конфигурации сервера. Солверы
+// для встреченных конфигураций строятся один раз и хранятся в Cache.
+package override
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aaa2ppp/multgen/internal/solver"
+)
+
+// Параметры запроса
+const (
+	ParamRTP   = "rtp"
+	ParamAlgo  = "algo"
+	ParamAlpha = "alpha"
+)
+
+// DefaultCacheSize — предел числа солверов в Cache, если Policy.CacheSize не задан.
+const DefaultCacheSize = 64
+
+// Policy — какие значения параметров принимаются. Нулевой Range или пустой
+// Algorithms запрещают соответствующий параметр.
+type Policy struct {
+	RTP        Range
+	Algorithms List
+	Alpha      Range
+	CacheSize  int // максимум солверов в кэше (0 - по умолчанию)
+}
+
+// Enabled сообщает, разрешён ли хоть один параметр.
+func (p Policy) Enabled() bool {
+	return !p.RTP.IsZero() || len(p.Algorithms) > 0 || !p.Alpha.IsZero()
+}
+
+// Validate проверяет, что алгоритмы из Algorithms зарегистрированы
+// (см. solver.Lookup).
+func (p Policy) Validate() error {
+	var errs []error
+	for _, name := range p.Algorithms {
+		if _, ok := solver.Lookup(name); !ok {
+			errs = append(errs, fmt.Errorf("unknown override algorithm %q", name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Check проверяет params на соответствие политике.
+func (p Policy) Check(params Params) error {
+	var errs []error
+
+	if params.RTP != 0 && !p.RTP.Contains(params.RTP) {
+		errs = append(errs, notAllowed(ParamRTP, strconv.FormatFloat(params.RTP, 'g', -1, 64), p.RTP.String()))
+	}
+
+	if params.Algorithm != "" && !slices.ContainsFunc(p.Algorithms, func(name string) bool {
+		return strings.EqualFold(name, params.Algorithm)
+	}) {
+		errs = append(errs, notAllowed(ParamAlgo, params.Algorithm, p.Algorithms.String()))
+	}
+
+	if params.Alpha != 0 && !p.Alpha.Contains(params.Alpha) {
+		errs = append(errs, notAllowed(ParamAlpha, strconv.FormatFloat(params.Alpha, 'g', -1, 64), p.Alpha.String()))
+	}
+
+	return errors.Join(errs...)
+}
+
+func notAllowed(param, value, allowed string) error {
+	if allowed == "" {
+		return fmt.Errorf("%s override is not allowed", param)
+	}
+	return fmt.Errorf("%s %s is not allowed, want %s", param, value, allowed)
+}
+
+// Range — отрезок [Min, Max]; как флаг задаётся "min:max" или одним числом.
+type Range struct {
+	Min, Max float64
+}
+
+func (r Range) IsZero() bool { return r == Range{} }
+
+func (r Range) Contains(v float64) bool { return !r.IsZero() && r.Min <= v && v <= r.Max }
+
+func (r Range) String() string {
+	if r.IsZero() {
+		return ""
+	}
+	return strconv.FormatFloat(r.Min, 'g', -1, 64) + ":" + strconv.FormatFloat(r.Max, 'g', -1, 64)
+}
+
+// Set реализует flag.Value.
+func (r *Range) Set(s string) error {
+	if s == "" {
+		*r = Range{}
+		return nil
+	}
+
+	lo, hi, ok := strings.Cut(s, ":")
+	if !ok {
+		hi = lo
+	}
+
+	min, err := strconv.ParseFloat(strings.TrimSpace(lo), 64)
+	if err != nil {
+		return err
+	}
+	max, err := strconv.ParseFloat(strings.TrimSpace(hi), 64)
+	if err != nil {
+		return err
+	}
+	if !(min <= max) {
+		return fmt.Errorf("want min <= max, got %s", s)
+	}
+
+	*r = Range{min, max}
+	return nil
+}
+
+// List — список строк; как флаг задаётся через запятую.
+type List []string
+
+func (l List) String() string { return strings.Join(l, ",") }
+
+// Set реализует flag.Value.
+func (l *List) Set(s string) error {
+	*l = nil
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
+
+// Params — параметры запроса; нулевое значение поля - не задано.
+type Params struct {
+	RTP       float64
+	Algorithm string
+	Alpha     float64
+}
+
+// ParseParams читает параметры через get (значение параметра запроса или "").
+// ok - задан хоть один параметр.
+func ParseParams(get func(name string) string) (params Params, ok bool, err error) {
+	parse := func(name string) float64 {
+		v := get(name)
+		if v == "" {
+			return 0
+		}
+		ok = true
+		f, e := strconv.ParseFloat(v, 64)
+		if e != nil || !(f > 0) {
+			err = errors.Join(err, fmt.Errorf("%s must be a number > 0, got %q", name, v))
+		}
+		return f
+	}
+
+	params.RTP = parse(ParamRTP)
+	params.Alpha = parse(ParamAlpha)
+	if params.Algorithm = get(ParamAlgo); params.Algorithm != "" {
+		ok = true
+	}
+
+	return params, ok, err
+}
+
+// Cache — солверы для конфигураций, полученных из текущей конфигурации
+// основного солвера и параметров запроса. Когда кэш полон, вытесняется
+// самый старый солвер.
+type Cache struct {
+	policy Policy
+	base   func() solver.Config // конфигурация основного солвера (меняется при перезагрузке)
+
+	mu      sync.Mutex
+	solvers map[solver.Config]*solver.Solver
+	order   []solver.Config // в порядке добавления
+}
+
+func NewCache(policy Policy, base func() solver.Config) *Cache {
+	if policy.CacheSize <= 0 {
+		policy.CacheSize = DefaultCacheSize
+	}
+	return &Cache{
+		policy:  policy,
+		base:    base,
+		solvers: make(map[solver.Config]*solver.Solver),
+	}
+}
+
+// Solver возвращает солвер для params: проверяет их политикой, накладывает
+// на конфигурацию основного солвера и берёт солвер из кэша или строит новый.
+// Ошибка - параметры не приняты или конфигурация некорректна (см. solver.New).
+func (c *Cache) Solver(params Params) (*solver.Solver, error) {
+	if err := c.policy.Check(params); err != nil {
+		return nil, err
+	}
+
+	// Зерно основного солвера не берём: с ним солвер параметров повторял бы
+	// потоки основного (см. Reloadable.Update).
+	cfg := c.base()
+	cfg.Seed = 0
+	if params.RTP != 0 {
+		cfg.RTP = params.RTP
+	}
+	if params.Algorithm != "" {
+		cfg.Algorithm = params.Algorithm
+	}
+	if params.Alpha != 0 {
+		cfg.Alpha = params.Alpha
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if s, ok := c.solvers[cfg]; ok {
+		return s, nil
+	}
+
+	s, err := solver.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(c.order) >= c.policy.CacheSize {
+		delete(c.solvers, c.order[0])
+		c.order = c.order[1:]
+	}
+	c.solvers[cfg] = s
+	c.order = append(c.order, cfg)
+
+	return s, nil
+}
+
+// Len возвращает число солверов в кэше.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.solvers)
+}
@@ -0,0 +1,137 @@
+package override_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/aaa2ppp/be"
+
+	"github.com/aaa2ppp/multgen/internal/api/override"
+	"github.com/aaa2ppp/multgen/internal/solver"
+)
+
+func TestParseParams(t *testing.T) {
+	tests := []struct {
+		query string
+		want  override.Params
+		ok    bool
+		err   any
+	}{
+		{"", override.Params{}, false, nil},
+		{"n=3", override.Params{}, false, nil},
+		{"rtp=0.95&n=3", override.Params{RTP: 0.95}, true, nil},
+		{"algo=crash&alpha=2", override.Params{Algorithm: "crash", Alpha: 2}, true, nil},
+		{"rtp=x", override.Params{}, true, "rtp must be a number > 0"},
+		{"alpha=-1", override.Params{}, true, "alpha must be a number > 0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := url.ParseQuery(tt.query)
+			be.Err(be.Require(t), err, nil)
+
+			params, ok, err := override.ParseParams(q.Get)
+			be.Equal(t, ok, tt.ok)
+			if tt.err != nil {
+				be.Err(t, err, tt.err)
+				return
+			}
+			be.Err(t, err, nil)
+			be.Equal(t, params, tt.want)
+		})
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	var p override.Policy
+	be.True(t, !p.Enabled())
+	be.Err(t, p.Check(override.Params{RTP: 0.9}), "rtp override is not allowed")
+
+	be.Err(be.Require(t), p.RTP.Set("0.8:0.99"), nil)
+	be.Err(be.Require(t), p.Algorithms.Set("crash, pareto1"), nil)
+	be.Err(be.Require(t), p.Alpha.Set("2"), nil)
+	be.True(t, p.Enabled())
+	be.Equal(t, p.RTP, override.Range{Min: 0.8, Max: 0.99})
+	be.Equal(t, p.Algorithms, override.List{"crash", "pareto1"})
+	be.Equal(t, p.Alpha.String(), "2:2")
+
+	be.Err(t, p.Check(override.Params{RTP: 0.8, Algorithm: "crash", Alpha: 2}), nil)
+	be.Err(t, p.Check(override.Params{RTP: 1}), "rtp 1 is not allowed, want 0.8:0.99")
+	be.Err(t, p.Check(override.Params{Algorithm: "max"}), "algo max is not allowed, want crash,pareto1")
+	be.Err(t, p.Check(override.Params{Algorithm: "Crash"}), nil)
+	be.Err(t, p.Validate(), nil)
+	be.Err(t, p.Check(override.Params{Alpha: 3}), "alpha 3 is not allowed, want 2:2")
+
+	be.Err(be.Require(t), p.Algorithms.Set("crash,crsh"), nil)
+	be.Err(t, p.Validate(), `unknown override algorithm "crsh"`)
+
+	var r override.Range
+	be.Err(t, r.Set("2:1"), "want min <= max")
+	be.Err(t, r.Set("a:1"))
+}
+
+func TestCache(t *testing.T) {
+	base := solver.DefaultConfig()
+	base.RTP = 0.97
+
+	c := override.NewCache(override.Policy{
+		RTP:        override.Range{Min: 0.5, Max: 1},
+		Algorithms: override.List{"crash", "max"},
+		CacheSize:  2,
+	}, func() solver.Config { return base })
+
+	s1, err := c.Solver(override.Params{RTP: 0.9})
+	be.Err(be.Require(t), err, nil)
+	be.Equal(t, s1.Config().RTP, 0.9)
+	be.Equal(t, s1.Config().Algorithm, base.Algorithm)
+
+	s2, err := c.Solver(override.Params{RTP: 0.9})
+	be.Err(be.Require(t), err, nil)
+	be.True(t, s1 == s2)
+
+	s3, err := c.Solver(override.Params{Algorithm: "max"})
+	be.Err(be.Require(t), err, nil)
+	be.Equal(t, s3.Config().RTP, 0.97)
+	be.Equal(t, s3.Config().Algorithm, "max")
+
+	// вытесняется самый старый
+	_, err = c.Solver(override.Params{Algorithm: "crash"})
+	be.Err(be.Require(t), err, nil)
+	be.Equal(t, c.Len(), 2)
+	s4, err := c.Solver(override.Params{RTP: 0.9})
+	be.Err(be.Require(t), err, nil)
+	be.True(t, s1 != s4)
+
+	_, err = c.Solver(override.Params{RTP: 0.4})
+	be.Err(t, err, "rtp 0.4 is not allowed")
+	_, err = c.Solver(override.Params{Alpha: 2})
+	be.Err(t, err, "alpha override is not allowed")
+}
+
+func TestCache_Seed(t *testing.T) {
+	base := solver.DefaultConfig()
+	base.Algorithm = "paretoA"
+	base.Alpha = 2
+	base.RTP = 0.97
+	base.Seed = 42
+
+	primary, err := solver.New(base)
+	be.Err(be.Require(t), err, nil)
+
+	c := override.NewCache(override.Policy{Alpha: override.Range{Min: 1, Max: 3}}, primary.Config)
+
+	// те же параметры, что у основного солвера: отличаться может только зерно
+	s, err := c.Solver(override.Params{Alpha: 2})
+	be.Err(be.Require(t), err, nil)
+	be.True(t, s.Seed() != primary.Seed())
+
+	const n = 1000
+	var same int
+	ms, ss := primary.NewStream(), s.NewStream()
+	for range n {
+		if m := ms.Solve(); m != base.Min && m == ss.Solve() {
+			same++
+		}
+	}
+	be.True(t, same < n/10)
+}
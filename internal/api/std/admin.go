@@ -0,0 +1,60 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/aaa2ppp/multgen/internal/api/admin"
+	"github.com/aaa2ppp/multgen/internal/solver"
+	"github.com/aaa2ppp/multgen/internal/stats"
+)
+
+// maxConfigBody — предел размера тела PUT /admin/config.
+const maxConfigBody = 1 << 16
+
+// requireToken пропускает только запросы с Authorization: Bearer <token>.
+func requireToken(token string, h http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !admin.Authorized([]byte(r.Header.Get("Authorization")), token) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	}
+}
+
+// getConfigHandler отдаёт текущую конфигурацию солвера.
+func getConfigHandler(rl admin.Reloader) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeConfig(w, r, rl.Config())
+	}
+}
+
+// putConfigHandler меняет конфигурацию солвера на лету (см. admin.UpdateConfig).
+func putConfigHandler(rl admin.Reloader, st *stats.Stats) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxConfigBody))
+		if err != nil {
+			http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		cfg, err := admin.UpdateConfig(rl, body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		st.SetMin(cfg.Min)
+
+		writeConfig(w, r, cfg)
+	}
+}
+
+func writeConfig(w http.ResponseWriter, r *http.Request, cfg solver.Config) {
+	w.Header().Set("content-type", "application/json")
+	if err := json.NewEncoder(w).Encode(cfg); err != nil {
+		logWriteError(r, err)
+	}
+}
@@ -4,18 +4,73 @@ import (
 	"log"
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/aaa2ppp/multgen/internal/api/admin"
 	"github.com/aaa2ppp/multgen/internal/api/buffer"
+	"github.com/aaa2ppp/multgen/internal/api/override"
+	"github.com/aaa2ppp/multgen/internal/format"
+	"github.com/aaa2ppp/multgen/internal/game"
+	"github.com/aaa2ppp/multgen/internal/metrics"
+	"github.com/aaa2ppp/multgen/internal/solver"
+	"github.com/aaa2ppp/multgen/internal/stats"
 )
 
 type Solver interface {
 	Solve() float64
+	SolvePrecision() (float64, int) // мультипликатор и его точность от одного солвера
+	Observe(bet float64, win bool, payout float64)
+	TheoreticalRTP(x float64) (float64, bool)
+	RoundStats() solver.RoundStats
 }
 
-func New(s Solver) *http.ServeMux {
+type Options struct {
+	BatchLimit     int              // максимальное n в /get?n=K (0 - game.DefaultBatchLimit)
+	StreamInterval time.Duration    // интервал между событиями /stream по умолчанию (0 - game.DefaultStreamInterval)
+	Metrics        *metrics.Metrics // nil - без метрик и /metrics
+	Stats          *stats.Stats     // nil - без /stats
+	Games          map[string]Game  // профили солвера на /games/{id}/...
+	Overrides      *override.Cache  // nil - параметры /get?rtp=&algo=&alpha= игнорируются
+
+	// Смена конфигурации солвера на лету через /admin/config
+	// (только вместе с непустым AdminToken).
+	Reloader   admin.Reloader
+	AdminToken string
+}
+
+func New(s Solver, opts Options) *http.ServeMux {
+	if opts.BatchLimit <= 0 {
+		opts.BatchLimit = game.DefaultBatchLimit
+	}
+	if opts.StreamInterval <= 0 {
+		opts.StreamInterval = game.DefaultStreamInterval
+	}
+
+	m := opts.Metrics
+	s = m.Instrument(s)
+	s = opts.Stats.Instrument(s)
+
 	mux := http.NewServeMux()
-	mux.Handle("GET /get", noCache(getHandler(s)))
-	mux.Handle("GET /ping", noCache(http.HandlerFunc(pong)))
+	handle := func(method, path string, h http.Handler) {
+		mux.Handle(method+" "+path, instrument(m.Route(path), h))
+	}
+
+	handle("GET", "/get", noCache(overrideHandler(opts.Overrides, s, opts.BatchLimit)))
+	handle("GET", "/stream", noCache(streamHandler(s, opts.StreamInterval)))
+	handle("POST", "/play", noCache(playHandler(s)))
+	handle("GET", "/ws", wsHandler(s))
+	handle("GET", "/ping", noCache(http.HandlerFunc(pong)))
+	handleGames(handle, opts.Games, opts.BatchLimit)
+	if m != nil {
+		handle("GET", "/metrics", noCache(metricsHandler(m)))
+	}
+	if opts.Stats != nil {
+		handle("GET", "/stats", noCache(statsHandler(opts.Stats, s)))
+	}
+	if opts.Reloader != nil && opts.AdminToken != "" {
+		handle("GET", "/admin/config", noCache(requireToken(opts.AdminToken, getConfigHandler(opts.Reloader))))
+		handle("PUT", "/admin/config", noCache(requireToken(opts.AdminToken, putConfigHandler(opts.Reloader, opts.Stats))))
+	}
 	return mux
 }
 
@@ -29,16 +84,21 @@ func pong(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func getHandler(s Solver) http.HandlerFunc {
+func getHandler(s Solver, batchLimit int) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		multiplier := s.Solve()
+		if r.URL.RawQuery != "" && r.URL.Query().Has("n") {
+			batchHandler(w, r, s, batchLimit)
+			return
+		}
+
+		multiplier, prec := s.SolvePrecision()
 
 		// one Get
 		buf := buffer.Get()
 
 		// The response is simple, so we may not use json package. It is for performance reasons.
 		buf = append(buf, `{"result":`...)
-		buf = strconv.AppendFloat(buf, multiplier, 'g', -1, 64)
+		buf = format.AppendMultiplier(buf, multiplier, prec)
 		buf = append(buf, '}')
 
 		w.Header().Set("content-type", "application/json")
@@ -52,3 +112,35 @@ func getHandler(s Solver) http.HandlerFunc {
 		buffer.Put(buf)
 	}
 }
+
+// batchHandler отвечает на /get?n=K массивом из K мультипликаторов: {"result":[m1,...]}.
+func batchHandler(w http.ResponseWriter, r *http.Request, s Solver, limit int) {
+	n, err := strconv.Atoi(r.URL.Query().Get("n"))
+	if err != nil || n < 1 || n > limit {
+		http.Error(w, "n must be an integer in [1, "+strconv.Itoa(limit)+"]", http.StatusBadRequest)
+		return
+	}
+
+	b := buffer.GetGrowable()
+	buf := b.B
+
+	buf = append(buf, `{"result":[`...)
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		m, prec := s.SolvePrecision()
+		buf = format.AppendMultiplier(buf, m, prec)
+	}
+	buf = append(buf, "]}"...)
+
+	w.Header().Set("content-type", "application/json")
+	w.Header().Set("content-length", strconv.Itoa(len(buf)))
+
+	if _, err := w.Write(buf); err != nil {
+		logWriteError(r, err)
+	}
+
+	b.B = buf
+	buffer.PutGrowable(b)
+}
@@ -1,6 +1,8 @@
 package api_test
 
 import (
+	"encoding/json"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
@@ -8,9 +10,14 @@ import (
 	"testing"
 
 	"github.com/aaa2ppp/be"
+	"github.com/fasthttp/websocket"
 
+	"github.com/aaa2ppp/multgen/internal/api/override"
 	api "github.com/aaa2ppp/multgen/internal/api/std"
+	"github.com/aaa2ppp/multgen/internal/game"
+	"github.com/aaa2ppp/multgen/internal/metrics"
 	"github.com/aaa2ppp/multgen/internal/solver"
+	"github.com/aaa2ppp/multgen/internal/stats"
 	"github.com/aaa2ppp/multgen/internal/testutils"
 )
 
@@ -20,7 +27,7 @@ func Test_GetHandler(t *testing.T) {
 	be.Err(t, err, nil)
 
 	// Хендлер
-	handler := api.New(s)
+	handler := api.New(s, api.Options{})
 
 	// Запрос
 	req := httptest.NewRequest(http.MethodGet, "/get", nil)
@@ -41,7 +48,7 @@ func Benchmark_getHandler(b *testing.B) {
 	s, err := solver.New(solver.DefaultConfig())
 	be.Err(b, err, nil)
 
-	handler := api.New(s)
+	handler := api.New(s, api.Options{})
 	req := httptest.NewRequest(http.MethodGet, "/get", nil)
 
 	b.ResetTimer()
@@ -59,7 +66,7 @@ func Benchmark_getHandler(b *testing.B) {
 func Test_PingHandler(t *testing.T) {
 	s, err := solver.New(solver.DefaultConfig())
 	be.Err(t, err, nil)
-	handler := api.New(s)
+	handler := api.New(s, api.Options{})
 
 	// Проверяем только статус и заголовки. Тело ответа неважно,
 	// может быть любым (например, "pong", поздравление с Новым годом и т.д.).
@@ -81,3 +88,357 @@ func Test_PingHandler(t *testing.T) {
 		})
 	}
 }
+
+func Test_GetHandler_Precision(t *testing.T) {
+	cfg := solver.DefaultConfig()
+	cfg.Algorithm = "max"
+	cfg.Precision = 2
+	s, err := solver.New(cfg)
+	be.Err(t, err, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/get", nil)
+	w := httptest.NewRecorder()
+	api.New(s, api.Options{}).ServeHTTP(w, req)
+
+	be.Equal(be.Require(t), w.Code, http.StatusOK)
+	be.Equal(t, w.Body.String(), `{"result":10000.00}`)
+}
+
+func Test_GetHandler_Batch(t *testing.T) {
+	s, err := solver.New(solver.DefaultConfig())
+	be.Err(t, err, nil)
+	handler := api.New(s, api.Options{BatchLimit: 3})
+
+	tests := []struct {
+		query string
+		code  int
+		body  string
+	}{
+		{"n=1", http.StatusOK, `{"result":[1]}`},
+		{"n=3", http.StatusOK, `{"result":[1,1,1]}`},
+		{"n=0", http.StatusBadRequest, ""},
+		{"n=4", http.StatusBadRequest, ""},
+		{"n=abc", http.StatusBadRequest, ""},
+		{"foo=bar", http.StatusOK, `{"result":1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/get?"+tt.query, nil)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			be.Equal(be.Require(t), w.Code, tt.code)
+			if tt.code == http.StatusOK {
+				be.Equal(t, w.Body.String(), tt.body)
+				be.Equal(t, w.Header().Get("content-length"), strconv.Itoa(len(tt.body)))
+			}
+		})
+	}
+}
+
+func Test_StreamHandler(t *testing.T) {
+	s, err := solver.New(solver.DefaultConfig())
+	be.Err(t, err, nil)
+	handler := api.New(s, api.Options{})
+
+	req := httptest.NewRequest(http.MethodGet, "/stream?n=3&interval=10ms", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	be.Equal(be.Require(t), w.Code, http.StatusOK)
+	be.Equal(t, w.Header().Get("content-type"), "text/event-stream")
+	be.True(t, w.Flushed)
+
+	var want strings.Builder
+	for id := 1; id <= 3; id++ {
+		want.WriteString("id: " + strconv.Itoa(id) + "\nevent: multiplier\ndata: {\"result\":1}\n\n")
+	}
+	be.Equal(t, w.Body.String(), want.String())
+
+	for _, query := range []string{"interval=1ms", "interval=abc", "n=-1"} {
+		t.Run(query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/stream?"+query, nil)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			be.Equal(t, w.Code, http.StatusBadRequest)
+		})
+	}
+}
+
+func Test_WSHandler(t *testing.T) {
+	cfg := solver.DefaultConfig()
+	cfg.Algorithm = "max"
+	s, err := solver.New(cfg)
+	be.Err(be.Require(t), err, nil)
+
+	srv := httptest.NewServer(api.New(s, api.Options{}))
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws", nil)
+	be.Err(be.Require(t), err, nil)
+	defer conn.Close()
+
+	play := func(bet game.Bet) game.Result {
+		be.Err(be.Require(t), conn.WriteJSON(bet), nil)
+		var res game.Result
+		be.Err(be.Require(t), conn.ReadJSON(&res), nil)
+		return res
+	}
+
+	// max всегда возвращает 10000
+	be.Equal(t, play(game.Bet{Bet: 2, X: 1.5}), game.Result{Round: 1, Outcome: game.Outcome{Multiplier: 10000, Win: true, Payout: 3}, RTP: 1.5})
+	be.Equal(t, play(game.Bet{Bet: 2, X: 20000}), game.Result{Round: 2, Outcome: game.Outcome{Multiplier: 10000}, RTP: 0.75})
+
+	// некорректные сообщения не закрывают соединение и не считаются раундом
+	for _, msg := range []string{`{"bet":0,"x":2}`, `{"bet":1,"x":0.5}`, `not json`} {
+		be.Err(be.Require(t), conn.WriteMessage(websocket.TextMessage, []byte(msg)), nil)
+		var resp struct{ Error string }
+		be.Err(be.Require(t), conn.ReadJSON(&resp), nil)
+		be.True(t, resp.Error != "")
+	}
+
+	be.Equal(t, play(game.Bet{Bet: 1, X: 9999}).Round, uint64(3))
+}
+
+func Test_PlayHandler(t *testing.T) {
+	cfg := solver.DefaultConfig()
+	cfg.Algorithm = "max"
+	s, err := solver.New(cfg)
+	be.Err(be.Require(t), err, nil)
+	handler := api.New(s, api.Options{})
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		code   int
+		want   string
+	}{
+		{"win", http.MethodPost, `{"bet":2,"target":1.5}`, http.StatusOK, `{"multiplier":10000,"win":true,"payout":3}`},
+		{"lose", http.MethodPost, `{"bet":2,"target":20000}`, http.StatusOK, `{"multiplier":10000,"win":false,"payout":0}`},
+		{"bad target", http.MethodPost, `{"bet":2,"target":0}`, http.StatusBadRequest, ""},
+		{"bad json", http.MethodPost, `{"bet":`, http.StatusBadRequest, ""},
+		{"get", http.MethodGet, "", http.StatusMethodNotAllowed, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/play", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			be.Equal(be.Require(t), w.Code, tt.code)
+			if tt.code == http.StatusOK {
+				be.Equal(t, w.Body.String(), tt.want)
+			}
+		})
+	}
+
+	// в статистику солвера попадают только сыгранные раунды
+	st := s.RoundStats()
+	be.Equal(t, st, solver.RoundStats{Rounds: 2, Wins: 1, Bet: 4, Payout: 3})
+	be.Equal(t, st.RTP(), 0.75)
+}
+
+func Test_MetricsHandler(t *testing.T) {
+	cfg := solver.DefaultConfig()
+	cfg.Algorithm = "max"
+	s, err := solver.New(cfg)
+	be.Err(be.Require(t), err, nil)
+	handler := api.New(s, api.Options{Metrics: metrics.New()})
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/get?n=3", nil),
+		httptest.NewRequest(http.MethodGet, "/get?n=0", nil),
+		httptest.NewRequest(http.MethodPost, "/play", strings.NewReader(`{"bet":2,"target":1.5}`)),
+		httptest.NewRequest(http.MethodPost, "/play", strings.NewReader(`{"bet":2,"target":20000}`)),
+	} {
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	be.Equal(be.Require(t), w.Code, http.StatusOK)
+
+	body := w.Body.String()
+	for _, want := range []string{
+		`multgen_http_requests_total{route="/get",code="200"} 1` + "\n",
+		`multgen_http_requests_total{route="/get",code="400"} 1` + "\n",
+		`multgen_http_requests_total{route="/play",code="200"} 2` + "\n",
+		`multgen_multiplier_count 5` + "\n",
+		`multgen_rounds_total 2` + "\n",
+		`multgen_realized_rtp 0.75` + "\n",
+	} {
+		be.True(t, strings.Contains(body, want))
+	}
+}
+
+func Test_StatsHandler(t *testing.T) {
+	cfg := solver.DefaultConfig()
+	cfg.Algorithm = "max"
+	cfg.RTP = 0.5
+	cfg.Seed = 1
+	s, err := solver.New(cfg)
+	be.Err(be.Require(t), err, nil)
+	handler := api.New(s, api.Options{Stats: stats.New(cfg.Min)})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stats", nil))
+	be.True(t, strings.Contains(w.Body.String(), `"rounds":{"count":0,"wins":0,"bet":0,"payout":0,"rtp":null}`))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/get?n=999", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/play", strings.NewReader(`{"bet":2,"target":1.5}`)))
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stats?x=2", nil))
+	be.Equal(be.Require(t), w.Code, http.StatusOK)
+
+	var report stats.Report
+	be.Err(be.Require(t), json.Unmarshal(w.Body.Bytes(), &report), nil)
+	be.Equal(t, report.Count, uint64(1000))
+	rs := s.RoundStats()
+	be.Equal(t, report.Rounds.Count, uint64(1))
+	be.Equal(t, report.Rounds.Bet, 2.0)
+	be.Equal(t, report.Rounds.Payout, rs.Payout)
+	be.Equal(be.Require(t), report.Rounds.RTP != nil, true)
+	be.Equal(t, *report.Rounds.RTP, rs.RTP())
+	be.Equal(t, report.Max, 10000.0)
+	be.True(t, math.Abs(report.SkimShare-0.5) < 0.05)
+	be.Equal(be.Require(t), report.Theoretical.X, 2.0)
+	be.Equal(t, *report.Theoretical.RTP, 1.0) // 2 * P(m > 2) = 2 * 0.5
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stats?x=-1", nil))
+	be.Equal(t, w.Code, http.StatusBadRequest)
+}
+
+func Test_GetHandler_Override(t *testing.T) {
+	s, err := solver.New(solver.DefaultConfig())
+	be.Err(be.Require(t), err, nil)
+	r := solver.NewReloadable(s)
+	cache := override.NewCache(override.Policy{Algorithms: override.List{"max"}}, r.Config)
+	handler := api.New(r, api.Options{Overrides: cache})
+
+	tests := []struct {
+		target string
+		code   int
+		want   string
+	}{
+		{"/get", http.StatusOK, `{"result":1}`},
+		{"/get?algo=max", http.StatusOK, `{"result":10000}`},
+		{"/get?algo=max&n=2", http.StatusOK, `{"result":[10000,10000]}`},
+		{"/get?algo=crash", http.StatusBadRequest, ""},
+		{"/get?rtp=0.9", http.StatusBadRequest, ""},
+		{"/get?rtp=abc", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			be.Equal(be.Require(t), w.Code, tt.code)
+			if tt.want != "" {
+				be.Equal(t, w.Body.String(), tt.want)
+			}
+		})
+	}
+	be.Equal(t, cache.Len(), 1)
+}
+
+func Test_Games(t *testing.T) {
+	s, err := solver.New(solver.DefaultConfig())
+	be.Err(be.Require(t), err, nil)
+
+	cfg := solver.DefaultConfig()
+	cfg.Algorithm = "max"
+	maxSolver, err := solver.New(cfg)
+	be.Err(be.Require(t), err, nil)
+	minSolver, err := solver.New(solver.DefaultConfig())
+	be.Err(be.Require(t), err, nil)
+
+	handler := api.New(s, api.Options{Games: map[string]api.Game{
+		"max": {Solver: maxSolver, Stats: stats.New(cfg.Min)},
+		"min": {Solver: minSolver},
+	}})
+
+	tests := []struct {
+		method string
+		target string
+		body   string
+		code   int
+		want   string
+	}{
+		{http.MethodGet, "/games/max/get", "", http.StatusOK, `{"result":10000}`},
+		{http.MethodGet, "/games/min/get?n=2", "", http.StatusOK, `{"result":[1,1]}`},
+		{http.MethodPost, "/games/max/play", `{"bet":2,"target":1.5}`, http.StatusOK, `{"multiplier":10000,"win":true,"payout":3}`},
+		{http.MethodGet, "/games/max/stats", "", http.StatusOK, ""},
+		{http.MethodGet, "/games/min/stats", "", http.StatusNotFound, ""},
+		{http.MethodGet, "/games/nope/get", "", http.StatusNotFound, ""},
+		{http.MethodGet, "/games/max/play", "", http.StatusMethodNotAllowed, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.target, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			be.Equal(be.Require(t), w.Code, tt.code)
+			if tt.want != "" {
+				be.Equal(t, w.Body.String(), tt.want)
+			}
+		})
+	}
+
+	// у каждого профиля своя статистика, основной солвер не задет
+	be.Equal(t, maxSolver.RoundStats().Rounds, uint64(1))
+	be.Equal(t, s.RoundStats().Rounds, uint64(0))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/games/max/stats", nil))
+	be.True(t, strings.Contains(w.Body.String(), `"rounds":{"count":1,"wins":1,"bet":2,"payout":3,"rtp":1.5}`))
+}
+
+func Test_AdminConfig(t *testing.T) {
+	s, err := solver.New(solver.DefaultConfig())
+	be.Err(be.Require(t), err, nil)
+	rs := solver.NewReloadable(s)
+	handler := api.New(rs, api.Options{Reloader: rs, AdminToken: "secret"})
+
+	get := func() string {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/get", nil))
+		return w.Body.String()
+	}
+
+	put := func(token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/admin/config", strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	be.Equal(t, get(), `{"result":1}`)
+
+	be.Equal(t, put("", `{"algorithm":"max"}`).Code, http.StatusUnauthorized)
+	be.Equal(t, put("wrong", `{"algorithm":"max"}`).Code, http.StatusUnauthorized)
+	be.Equal(t, put("secret", `{"rtp":2}`).Code, http.StatusBadRequest)
+	be.Equal(t, put("secret", `{"foo":1}`).Code, http.StatusBadRequest)
+	be.Equal(t, put("secret", `{"algorithm":"typo"}`).Code, http.StatusBadRequest)
+	be.Equal(t, put("secret", `{"algorithm":"table","dist_file":"/etc/passwd"}`).Code, http.StatusBadRequest)
+	be.Equal(t, get(), `{"result":1}`) // после ошибок солвер прежний
+
+	w := put("secret", `{"algorithm":"max"}`)
+	be.Equal(be.Require(t), w.Code, http.StatusOK)
+
+	var cfg solver.Config
+	be.Err(be.Require(t), json.Unmarshal(w.Body.Bytes(), &cfg), nil)
+	be.Equal(t, cfg.Algorithm, "max")
+	be.Equal(t, cfg.RTP, 1.0) // остальные поля сохранены
+	be.Equal(t, get(), `{"result":10000}`)
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"maps"
+	"net/http"
+
+	"github.com/aaa2ppp/multgen/internal/stats"
+)
+
+// Game — именованный профиль солвера, обслуживается на /games/{id}/...
+type Game struct {
+	Solver Solver
+	Stats  *stats.Stats // nil - без /games/{id}/stats
+}
+
+// handleGames регистрирует маршруты профилей. Телеметрия мультипликаторов и
+// раундов (/metrics) — только по основному солверу, у профилей своя /stats.
+func handleGames(handle func(method, path string, h http.Handler), games map[string]Game, batchLimit int) {
+	if len(games) == 0 {
+		return
+	}
+
+	games = maps.Clone(games)
+	for id, g := range games {
+		g.Solver = g.Stats.Instrument(g.Solver)
+		games[id] = g
+	}
+
+	handle("GET", "/games/{id}/get", noCache(gameRoute(games, func(g Game) http.Handler {
+		return getHandler(g.Solver, batchLimit)
+	})))
+	handle("POST", "/games/{id}/play", noCache(gameRoute(games, func(g Game) http.Handler {
+		return playHandler(g.Solver)
+	})))
+	handle("GET", "/games/{id}/stats", noCache(gameRoute(games, func(g Game) http.Handler {
+		if g.Stats == nil {
+			return nil
+		}
+		return statsHandler(g.Stats, g.Solver)
+	})))
+}
+
+// gameRoute строит обработчик каждого профиля заранее и выбирает его по id
+// из пути. Неизвестный id (или nil от h) — 404.
+func gameRoute(games map[string]Game, h func(Game) http.Handler) http.HandlerFunc {
+	handlers := make(map[string]http.Handler, len(games))
+	for id, g := range games {
+		if h := h(g); h != nil {
+			handlers[id] = h
+		}
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		h, ok := handlers[r.PathValue("id")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		h.ServeHTTP(w, r)
+	}
+}
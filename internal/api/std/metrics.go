@@ -0,0 +1,78 @@
+package api
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aaa2ppp/multgen/internal/api/buffer"
+	"github.com/aaa2ppp/multgen/internal/metrics"
+)
+
+// statusRecorder запоминает код ответа для метрик.
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (w *statusRecorder) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusRecorder) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Hijack для WebSocket: ответ 101 пишет уже сам Upgrader в соединение.
+func (w *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, brw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil {
+		w.code = http.StatusSwitchingProtocols
+	}
+	return conn, brw, err
+}
+
+// Unwrap для http.ResponseController (Flush в /stream).
+func (w *statusRecorder) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+// instrument учитывает запросы к маршруту route: код ответа и время обработки.
+func instrument(route *metrics.Route, h http.Handler) http.Handler {
+	if route == nil {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		h.ServeHTTP(rec, r)
+		if rec.code == 0 {
+			rec.code = http.StatusOK
+		}
+		route.Observe(rec.code, time.Since(start))
+	})
+}
+
+// metricsHandler отдаёт метрики в текстовом формате Prometheus.
+func metricsHandler(m *metrics.Metrics) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		b := buffer.GetGrowable()
+		buf := m.AppendText(b.B)
+
+		w.Header().Set("content-type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Header().Set("content-length", strconv.Itoa(len(buf)))
+
+		if _, err := w.Write(buf); err != nil {
+			logWriteError(r, err)
+		}
+
+		b.B = buf
+		buffer.PutGrowable(b)
+	}
+}
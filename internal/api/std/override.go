@@ -0,0 +1,42 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/aaa2ppp/multgen/internal/api/override"
+)
+
+// overrideHandler — /get с параметрами rtp, algo, alpha: мультипликаторы
+// выдаёт солвер из c для этой конфигурации (без телеметрии и /stats основного
+// солвера). Без параметров или при nil c — как getHandler.
+func overrideHandler(c *override.Cache, s Solver, batchLimit int) http.HandlerFunc {
+	get := getHandler(s, batchLimit)
+	if c == nil {
+		return get
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.RawQuery == "" {
+			get(w, r)
+			return
+		}
+
+		params, ok, err := override.ParseParams(r.URL.Query().Get)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !ok {
+			get(w, r)
+			return
+		}
+
+		custom, err := c.Solver(params)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		getHandler(custom, batchLimit)(w, r)
+	}
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/aaa2ppp/multgen/internal/api/buffer"
+	"github.com/aaa2ppp/multgen/internal/game"
+)
+
+// maxPlayBody — предел размера тела запроса /play.
+const maxPlayBody = 1 << 10
+
+// playHandler разыгрывает раунд на сервере: принимает {"bet":1,"target":2},
+// отвечает {"multiplier":m,"win":true,"payout":2} и сообщает итог солверу.
+func playHandler(s Solver) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req game.PlayRequest
+		dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxPlayBody))
+		if err := dec.Decode(&req); err != nil {
+			http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		bet := game.Bet{Bet: req.Bet, X: req.Target}
+		if err := bet.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		outcome := game.Play(s, bet)
+
+		b := buffer.GetGrowable()
+		buf := outcome.AppendJSON(b.B)
+
+		w.Header().Set("content-type", "application/json")
+		w.Header().Set("content-length", strconv.Itoa(len(buf)))
+
+		if _, err := w.Write(buf); err != nil {
+			logWriteError(r, err)
+		}
+
+		b.B = buf
+		buffer.PutGrowable(b)
+	}
+}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/aaa2ppp/multgen/internal/stats"
+)
+
+// statsHandler отдаёт статистику выданных мультипликаторов с момента старта
+// и, если задан параметр x, теоретический RTP для x.
+func statsHandler(st *stats.Stats, s Solver) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var x float64
+		if v := r.URL.Query().Get("x"); v != "" {
+			var err error
+			if x, err = strconv.ParseFloat(v, 64); err != nil || !(x > 0) || math.IsInf(x, 0) {
+				http.Error(w, "x must be a finite number > 0", http.StatusBadRequest)
+				return
+			}
+		}
+
+		body, err := json.Marshal(st.Report(s, x))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("content-type", "application/json")
+		if _, err := w.Write(append(body, '\n')); err != nil {
+			logWriteError(r, err)
+		}
+	}
+}
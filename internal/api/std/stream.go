@@ -0,0 +1,99 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aaa2ppp/multgen/internal/api/buffer"
+	"github.com/aaa2ppp/multgen/internal/format"
+	"github.com/aaa2ppp/multgen/internal/game"
+)
+
+// streamHandler отдаёт мультипликаторы как Server-Sent Events, по одному
+// событию (раунду) за интервал:
+//
+//	id: 1
+//	event: multiplier
+//	data: {"result":1.5}
+//
+// Параметры запроса: interval — интервал между раундами (по умолчанию
+// Options.StreamInterval, не меньше game.MinStreamInterval), n — число событий,
+// после которого поток закрывается (0 или не задан - без ограничения).
+func streamHandler(s Solver, defaultInterval time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		interval := defaultInterval
+		if v := r.URL.Query().Get("interval"); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d < game.MinStreamInterval {
+				http.Error(w, "interval must be a duration >= "+game.MinStreamInterval.String(), http.StatusBadRequest)
+				return
+			}
+			interval = d
+		}
+
+		var n uint64
+		if v := r.URL.Query().Get("n"); v != "" {
+			var err error
+			if n, err = strconv.ParseUint(v, 10, 64); err != nil {
+				http.Error(w, "n must be a non-negative integer", http.StatusBadRequest)
+				return
+			}
+		}
+
+		rc := http.NewResponseController(w)
+
+		// поток живёт дольше WriteTimeout сервера
+		if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			logWriteError(r, err)
+			return
+		}
+
+		w.Header().Set("content-type", "text/event-stream")
+		w.Header().Set("x-accel-buffering", "no") // не буферизовать в nginx
+		w.WriteHeader(http.StatusOK)
+		if err := rc.Flush(); err != nil {
+			logWriteError(r, err)
+			return
+		}
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for id := uint64(1); ; id++ {
+			buf := buffer.GetGrowable()
+			b := buf.B
+
+			b = append(b, "id: "...)
+			b = strconv.AppendUint(b, id, 10)
+			b = append(b, "\nevent: multiplier\ndata: {\"result\":"...)
+			m, prec := s.SolvePrecision()
+			b = format.AppendMultiplier(b, m, prec)
+			b = append(b, "}\n\n"...)
+
+			_, err := w.Write(b)
+			if err == nil {
+				err = rc.Flush()
+			}
+
+			buf.B = b
+			buffer.PutGrowable(buf)
+
+			if err != nil {
+				logWriteError(r, err)
+				return
+			}
+
+			if n != 0 && id == n {
+				return
+			}
+
+			select {
+			case <-r.Context().Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/fasthttp/websocket"
+
+	"github.com/aaa2ppp/multgen/internal/api/ws"
+)
+
+var upgrader = websocket.Upgrader{}
+
+// wsHandler — протокол раундов поверх WebSocket (см. пакет ws).
+func wsHandler(s Solver) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return // Upgrade уже ответил клиенту ошибкой
+		}
+
+		// контекст запроса отменяется при остановке сервера (см. BaseContext в multgen)
+		ws.Serve(r.Context(), conn, s)
+	}
+}
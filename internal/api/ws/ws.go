@@ -0,0 +1,85 @@
+// Package ws реализует протокол раундов поверх WebSocket, общий для
+// net/http и fasthttp серверов.
+//
+// Клиент шлёт текстовые сообщения-ставки {"bet":1,"x":2.5}, на каждую
+// сервер отвечает итогом раунда
+//
+//	{"round":1,"multiplier":3.14,"win":true,"payout":2.5,"rtp":2.5}
+//
+// где rtp — фактический RTP соединения. Раунды учитываются в статистике
+// солвера (Solver.Observe). На некорректную ставку сервер
+// отвечает {"error":"..."}, соединение при этом не закрывается.
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/fasthttp/websocket"
+
+	"github.com/aaa2ppp/multgen/internal/game"
+)
+
+// IdleTimeout — соединение закрывается, если клиент молчит дольше.
+const IdleTimeout = 5 * time.Minute
+
+type Solver = game.Solver
+
+type errorMessage struct {
+	Error string `json:"error"`
+}
+
+// Serve обслуживает соединение до его закрытия клиентом, ошибки или отмены ctx.
+func Serve(ctx context.Context, conn *websocket.Conn, s Solver) {
+	defer conn.Close()
+
+	stop := context.AfterFunc(ctx, func() {
+		msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutdown")
+		_ = conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+		conn.Close()
+	})
+	defer stop()
+
+	var session game.Session
+	for {
+		if err := conn.SetReadDeadline(time.Now().Add(IdleTimeout)); err != nil {
+			logError(conn, err)
+			return
+		}
+
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			if ctx.Err() == nil && !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				logError(conn, err)
+			}
+			return
+		}
+
+		var bet game.Bet
+		if err := json.Unmarshal(msg, &bet); err != nil {
+			if err := conn.WriteJSON(errorMessage{"bad message: " + err.Error()}); err != nil {
+				logError(conn, err)
+				return
+			}
+			continue
+		}
+
+		var resp any
+		if err := bet.Validate(); err != nil {
+			resp = errorMessage{err.Error()}
+		} else {
+			resp = session.Add(bet, game.Play(s, bet))
+		}
+
+		if err := conn.WriteJSON(resp); err != nil {
+			logError(conn, err)
+			return
+		}
+	}
+}
+
+func logError(conn *websocket.Conn, err error) {
+	log.Printf("ws %s: %v", conn.RemoteAddr(), err)
+}
@@ -0,0 +1,113 @@
+package checker
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+// Bankroll — правила игры с банкроллом: игрок начинает с баланса Start,
+// ставит Stake (или долю Fraction текущего баланса) и заканчивает сессию,
+// когда разорён или сыграл Rounds раундов.
+type Bankroll struct {
+	Start    float64 // начальный баланс
+	Stake    float64 // ставка; при Fraction > 0 - минимальная ставка
+	Fraction float64 // доля текущего баланса на ставку (0 - фиксированная ставка Stake)
+	Rounds   int     // длина сессии (0 - без ограничения)
+}
+
+func (b Bankroll) Validate() error {
+	var errs []error
+
+	if !(b.Stake > 0) {
+		errs = append(errs, errors.New("stake must be > 0"))
+	}
+	if !(b.Start >= b.Stake) {
+		errs = append(errs, errors.New("bankroll must be >= stake"))
+	}
+	if !(b.Fraction >= 0 && b.Fraction <= 1) {
+		errs = append(errs, errors.New("stake fraction must be in [0, 1]"))
+	}
+	if b.Rounds < 0 {
+		errs = append(errs, errors.New("rounds must be >= 0"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Session — сессия одного игрока.
+type Session struct {
+	Balance float64
+	Rounds  int  // сыграно раундов
+	Ruined  bool // баланса не хватает на ставку Stake
+}
+
+func NewSession(b Bankroll) Session {
+	return Session{Balance: b.Start}
+}
+
+// Active сообщает, может ли игрок продолжать сессию.
+func (s *Session) Active(b Bankroll) bool {
+	return !s.Ruined && (b.Rounds == 0 || s.Rounds < b.Rounds)
+}
+
+// Play разыгрывает раунд. Ставка — Stake или доля Fraction баланса (не
+// меньше Stake), умноженная на k, но не больше баланса. ratio — выплата на
+// единицу ставки (0 - проигрыш).
+func (s *Session) Play(b Bankroll, k, ratio float64) (bet, payout float64) {
+	bet = b.Stake
+	if b.Fraction > 0 {
+		bet = max(b.Fraction*s.Balance, b.Stake)
+	}
+	bet = min(bet*k, s.Balance)
+	payout = bet * ratio
+
+	s.Balance += payout - bet
+	s.Rounds++
+	if s.Balance < b.Stake {
+		s.Ruined = true
+	}
+
+	return bet, payout
+}
+
+// Distribution — сводка распределения: среднее, крайние значения и квантили.
+type Distribution struct {
+	Mean float64 `json:"mean"`
+	Min  float64 `json:"min"`
+	P5   float64 `json:"p5"`
+	P25  float64 `json:"p25"`
+	P50  float64 `json:"p50"`
+	P75  float64 `json:"p75"`
+	P95  float64 `json:"p95"`
+	Max  float64 `json:"max"`
+}
+
+// Describe возвращает сводку распределения values.
+func Describe(values []float64) (Distribution, error) {
+	if len(values) == 0 {
+		return Distribution{}, fmt.Errorf("empty input slice")
+	}
+
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	q := func(p float64) float64 { return stat.Quantile(p, stat.Empirical, sorted, nil) }
+	return Distribution{
+		Mean: stat.Mean(sorted, nil),
+		Min:  sorted[0],
+		P5:   q(0.05),
+		P25:  q(0.25),
+		P50:  q(0.50),
+		P75:  q(0.75),
+		P95:  q(0.95),
+		Max:  sorted[len(sorted)-1],
+	}, nil
+}
+
+// Values возвращает поля в порядке объявления.
+func (d Distribution) Values() []float64 {
+	return []float64{d.Mean, d.Min, d.P5, d.P25, d.P50, d.P75, d.P95, d.Max}
+}
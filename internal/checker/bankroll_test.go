@@ -0,0 +1,75 @@
+package checker
+
+import (
+	"testing"
+
+	"github.com/aaa2ppp/be"
+)
+
+func TestBankroll_Validate(t *testing.T) {
+	be.Err(t, Bankroll{Start: 10, Stake: 1}.Validate(), nil)
+	be.Err(t, Bankroll{Start: 10, Stake: 0}.Validate(), "stake must be > 0")
+	be.Err(t, Bankroll{Start: 1, Stake: 2}.Validate(), "bankroll must be >= stake")
+	be.Err(t, Bankroll{Start: 10, Stake: 1, Fraction: 2}.Validate(), "stake fraction")
+	be.Err(t, Bankroll{Start: 10, Stake: 1, Rounds: -1}.Validate(), "rounds must be >= 0")
+}
+
+func TestSession(t *testing.T) {
+	t.Run("fixed stake, ruin", func(t *testing.T) {
+		b := Bankroll{Start: 3, Stake: 1}
+		s := NewSession(b)
+
+		bet, payout := s.Play(b, 1, 2) // выигрыш x2
+		be.Equal(t, bet, 1.0)
+		be.Equal(t, payout, 2.0)
+		be.Equal(t, s.Balance, 4.0)
+
+		for s.Active(b) {
+			s.Play(b, 1, 0)
+		}
+		be.True(t, s.Ruined)
+		be.Equal(t, s.Rounds, 5)
+		be.Equal(t, s.Balance, 0.0)
+	})
+
+	t.Run("stake is capped by balance", func(t *testing.T) {
+		b := Bankroll{Start: 3, Stake: 1}
+		s := NewSession(b)
+		bet, _ := s.Play(b, 4, 0)
+		be.Equal(t, bet, 3.0)
+		be.True(t, s.Ruined)
+	})
+
+	t.Run("fraction", func(t *testing.T) {
+		b := Bankroll{Start: 100, Stake: 1, Fraction: 0.1}
+		s := NewSession(b)
+		bet, _ := s.Play(b, 1, 0)
+		be.Equal(t, bet, 10.0)
+		bet, _ = s.Play(b, 1, 0)
+		be.Equal(t, bet, 9.0)
+		be.Equal(t, s.Balance, 81.0)
+	})
+
+	t.Run("rounds", func(t *testing.T) {
+		b := Bankroll{Start: 10, Stake: 1, Rounds: 2}
+		s := NewSession(b)
+		s.Play(b, 1, 1)
+		be.True(t, s.Active(b))
+		s.Play(b, 1, 1)
+		be.True(t, !s.Active(b))
+		be.True(t, !s.Ruined)
+	})
+}
+
+func TestDescribe(t *testing.T) {
+	d, err := Describe([]float64{5, 1, 4, 2, 3})
+	be.Err(t, err, nil)
+	be.Equal(t, d.Mean, 3.0)
+	be.Equal(t, d.Min, 1.0)
+	be.Equal(t, d.P50, 3.0)
+	be.Equal(t, d.Max, 5.0)
+	be.Equal(t, d.Values(), []float64{3, 1, 1, 2, 3, 4, 5, 5})
+
+	_, err = Describe(nil)
+	be.Err(t, err)
+}
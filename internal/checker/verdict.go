@@ -0,0 +1,52 @@
+package checker
+
+import (
+	"fmt"
+	"math"
+)
+
+// Verdict — итог проверки RTP: ожидаемый RTP должен попасть в доверительный
+// интервал уровня CL, расширенный на Tolerance.
+type Verdict struct {
+	Expected  float64 `json:"expected"`
+	CL        float64 `json:"cl"`
+	Tolerance float64 `json:"tolerance"`
+	RTP       float64 `json:"rtp"`
+	Lo        float64 `json:"lo"`
+	Hi        float64 `json:"hi"`
+	Passed    bool    `json:"passed"`
+}
+
+// Verify проверяет, что RTP игроков rtps согласуется с ожидаемым expected.
+// С одним игроком интервал вырождается в точку, и решает только tolerance.
+func Verify(rtps []float64, expected, cl, tolerance float64) (Verdict, error) {
+	if !(tolerance >= 0) {
+		return Verdict{}, fmt.Errorf("tolerance must be >= 0")
+	}
+
+	rtp, lo, hi, err := ConfidenceInterval(rtps, cl)
+	if err != nil {
+		return Verdict{}, err
+	}
+
+	return Verdict{
+		Expected:  expected,
+		CL:        cl,
+		Tolerance: tolerance,
+		RTP:       rtp,
+		Lo:        lo,
+		Hi:        hi,
+		Passed:    lo-tolerance <= expected && expected <= hi+tolerance && !math.IsNaN(rtp),
+	}, nil
+}
+
+// String возвращает вердикт в духе платформы проверки:
+// "RTP 0.9337 ✓ PASSED (expected 0.95 in [0.9301, 0.9373] ±0.02 at 95%)".
+func (v Verdict) String() string {
+	status := "✓ PASSED"
+	if !v.Passed {
+		status = "✗ FAILED"
+	}
+	return fmt.Sprintf("RTP %.4f %s (expected %g in [%.4f, %.4f] ±%g at %g%%)",
+		v.RTP, status, v.Expected, v.Lo, v.Hi, v.Tolerance, v.CL*100)
+}
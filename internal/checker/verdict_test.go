@@ -0,0 +1,56 @@
+package checker
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/aaa2ppp/be"
+)
+
+func TestVerify(t *testing.T) {
+	rtps := []float64{0.94, 0.95, 0.96, 0.95, 0.94, 0.96}
+
+	t.Run("inside CI", func(t *testing.T) {
+		v, err := Verify(rtps, 0.95, 0.95, 0)
+		be.Err(t, err, nil)
+		be.True(t, v.Passed)
+		be.True(t, v.Lo < 0.95 && 0.95 < v.Hi)
+	})
+
+	t.Run("outside CI", func(t *testing.T) {
+		v, err := Verify(rtps, 0.90, 0.95, 0)
+		be.Err(t, err, nil)
+		be.True(t, !v.Passed)
+		be.True(t, strings.HasPrefix(v.String(), "RTP 0.9500 ✗ FAILED (expected 0.9 in ["))
+	})
+
+	t.Run("tolerance widens CI", func(t *testing.T) {
+		v, err := Verify(rtps, 0.90, 0.95, 0.05)
+		be.Err(t, err, nil)
+		be.True(t, v.Passed)
+	})
+
+	t.Run("one player", func(t *testing.T) {
+		v, err := Verify([]float64{0.93}, 0.95, 0.95, 0.01)
+		be.Err(t, err, nil)
+		be.True(t, !v.Passed)
+
+		v, err = Verify([]float64{0.93}, 0.95, 0.95, 0.02)
+		be.Err(t, err, nil)
+		be.True(t, v.Passed)
+	})
+
+	t.Run("NaN", func(t *testing.T) {
+		v, err := Verify([]float64{math.NaN()}, 0.95, 0.95, 1)
+		be.Err(t, err, nil)
+		be.True(t, !v.Passed)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := Verify(rtps, 0.95, 1, 0)
+		be.Err(t, err, "confidenceLevel")
+		_, err = Verify(rtps, 0.95, 0.95, -1)
+		be.Err(t, err, "tolerance")
+	})
+}
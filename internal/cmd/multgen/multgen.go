@@ -10,36 +10,51 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
-	"strconv"
 	"sync"
 	"syscall"
 	"time"
 
 	"github.com/valyala/fasthttp"
+	"google.golang.org/grpc"
 
 	fastapi "github.com/aaa2ppp/multgen/internal/api/fast"
+	grpcapi "github.com/aaa2ppp/multgen/internal/api/grpc"
+	"github.com/aaa2ppp/multgen/internal/api/grpc/multgenpb"
+	"github.com/aaa2ppp/multgen/internal/api/override"
 	api "github.com/aaa2ppp/multgen/internal/api/std"
 	"github.com/aaa2ppp/multgen/internal/config"
+	"github.com/aaa2ppp/multgen/internal/format"
+	"github.com/aaa2ppp/multgen/internal/metrics"
 	"github.com/aaa2ppp/multgen/internal/solver"
+	"github.com/aaa2ppp/multgen/internal/stats"
 )
 
 func Main(tune config.Config) {
 	cfg := config.MustLoad(tune)
-	log.Printf("cfg: %+v", cfg)
+	log.Printf("cfg: %+v", cfg.Redacted())
 
-	solver, err := solver.New(cfg.Solver)
+	s, err := solver.New(cfg.Solver)
 	if err != nil {
 		log.Fatalf("can't create solver: %v", err)
 	}
+	log.Printf("seed: %d", s.Seed())
 
 	var exitCode int
 	if cfg.CLIMode {
-		exitCode = runAsCLI(os.Stdin, os.Stdout, solver)
+		exitCode = runAsCLI(os.Stdin, os.Stdout, s)
 	} else {
-		if cfg.Server.FastHTTP {
-			exitCode = runAsFastHTTPServer(cfg.Server, solver)
+		games, err := newGames(cfg)
+		if err != nil {
+			log.Fatalf("can't create solver: %v", err)
+		}
+
+		rs := solver.NewReloadable(s)
+		if cfg.Server.GRPC {
+			exitCode = runAsGRPCServer(cfg, rs)
+		} else if cfg.Server.FastHTTP {
+			exitCode = runAsFastHTTPServer(cfg, rs, games)
 		} else {
-			exitCode = runAsHTTPServer(cfg.Server, solver)
+			exitCode = runAsHTTPServer(cfg, rs, games)
 		}
 		log.Printf("exit with code: %d", exitCode)
 	}
@@ -47,12 +62,85 @@ func Main(tune config.Config) {
 	os.Exit(exitCode)
 }
 
-func runAsFastHTTPServer(cfg config.Server, s *solver.Solver) int {
-	api := fastapi.New(s)
+func newMetrics(cfg config.Server) *metrics.Metrics {
+	if !cfg.Metrics {
+		return nil
+	}
+	return metrics.New()
+}
+
+func newStats(cfg config.Server, s solver.Config) *stats.Stats {
+	if !cfg.Stats {
+		return nil
+	}
+	return stats.New(s.Min)
+}
+
+// newOverrides — кэш солверов для /get?rtp=&algo=&alpha=, если политика
+// что-то разрешает; конфигурации строятся от текущей конфигурации s.
+func newOverrides(cfg config.Server, s *solver.Reloadable) *override.Cache {
+	if !cfg.Overrides.Enabled() {
+		return nil
+	}
+	return override.NewCache(cfg.Overrides, s.Config)
+}
+
+// game — профиль солвера из cfg.Games со своей статистикой.
+type game struct {
+	solver *solver.Solver
+	stats  *stats.Stats
+}
+
+func newGames(cfg config.Config) (map[string]game, error) {
+	games := make(map[string]game, len(cfg.Games))
+	for id, gcfg := range cfg.Games {
+		s, err := solver.New(gcfg)
+		if err != nil {
+			return nil, fmt.Errorf("game %s: %w", id, err)
+		}
+		log.Printf("game %s: seed: %d", id, s.Seed())
+		games[id] = game{s, newStats(cfg.Server, gcfg)}
+	}
+	return games, nil
+}
+
+// shutdownTimeout — сколько ждать завершения текущих запросов при остановке.
+const shutdownTimeout = 10 * time.Second
+
+// waitShutdownSignal ждёт сигнала остановки сервера.
+func waitShutdownSignal() os.Signal {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	return <-c
+}
+
+func runAsFastHTTPServer(cfg config.Config, s *solver.Reloadable, games map[string]game) int {
+	fastGames := make(map[string]fastapi.Game, len(games))
+	for id, g := range games {
+		fastGames[id] = fastapi.Game{Solver: g.solver, Stats: g.stats}
+	}
+
+	// Сессии /ws живут в перехваченных соединениях вне обработчика: при
+	// остановке их закрывает ctx, а дожидается sessions.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var sessions sync.WaitGroup
+
+	api := fastapi.New(s, fastapi.Options{
+		BatchLimit: cfg.Server.BatchLimit,
+		Metrics:    newMetrics(cfg.Server),
+		Stats:      newStats(cfg.Server, cfg.Solver),
+		Games:      fastGames,
+		Overrides:  newOverrides(cfg.Server, s),
+		Reloader:   s,
+		AdminToken: cfg.Server.AdminToken,
+		Context:    ctx,
+		Sessions:   &sessions,
+	})
 
-	listener, err := net.Listen("tcp", cfg.Addr)
+	listener, err := net.Listen("tcp", cfg.Server.Addr)
 	if err != nil {
-		log.Printf("failed to listen on %s: %v", cfg.Addr, err)
+		log.Printf("failed to listen on %s: %v", cfg.Server.Addr, err)
 		return 1
 	}
 
@@ -67,23 +155,23 @@ func runAsFastHTTPServer(cfg config.Server, s *solver.Solver) int {
 	go func() {
 		defer close(done)
 
-		c := make(chan os.Signal, 1)
-		signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-		sig := <-c
+		sig := waitShutdownSignal()
 		log.Printf("shutdown by signal: %v", sig)
 
 		if err := listener.Close(); err != nil {
 			log.Printf("can't close listener: %v", err)
 			done <- 1
 		}
+		cancel()
 
 		finished := make(chan struct{})
 		go func() {
 			wg.Wait()
+			sessions.Wait()
 			close(finished)
 		}()
 
-		tm := time.NewTimer(10 * time.Second)
+		tm := time.NewTimer(shutdownTimeout)
 		select {
 		case <-finished:
 		case <-tm.C:
@@ -92,7 +180,7 @@ func runAsFastHTTPServer(cfg config.Server, s *solver.Solver) int {
 		}
 	}()
 
-	log.Printf("fasthttp server listens on %v", cfg.Addr)
+	log.Printf("fasthttp server listens on %v", cfg.Server.Addr)
 	if err := fasthttp.Serve(listener, wrappedHandler); err != nil && err != net.ErrClosed {
 		log.Printf("fasthttp server fail: %v", err)
 		return 1
@@ -101,26 +189,45 @@ func runAsFastHTTPServer(cfg config.Server, s *solver.Solver) int {
 	return <-done
 }
 
-func runAsHTTPServer(cfg config.Server, s *solver.Solver) int {
-	api := api.New(s)
+func runAsHTTPServer(cfg config.Config, s *solver.Reloadable, games map[string]game) int {
+	stdGames := make(map[string]api.Game, len(games))
+	for id, g := range games {
+		stdGames[id] = api.Game{Solver: g.solver, Stats: g.stats}
+	}
+
+	api := api.New(s, api.Options{
+		BatchLimit:     cfg.Server.BatchLimit,
+		StreamInterval: cfg.Server.StreamInterval,
+		Metrics:        newMetrics(cfg.Server),
+		Stats:          newStats(cfg.Server, cfg.Solver),
+		Games:          stdGames,
+		Overrides:      newOverrides(cfg.Server, s),
+		Reloader:       s,
+		AdminToken:     cfg.Server.AdminToken,
+	})
+
+	// Контекст запросов отменяется при Shutdown, иначе открытые /stream
+	// не дадут серверу завершиться.
+	baseCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
 	server := &http.Server{
-		Addr:         cfg.Addr,
+		Addr:         cfg.Server.Addr,
 		Handler:      api,
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
+		BaseContext:  func(net.Listener) context.Context { return baseCtx },
 	}
+	server.RegisterOnShutdown(cancel)
 
 	done := make(chan int)
 	go func() {
 		defer close(done)
 
-		c := make(chan os.Signal, 1)
-		signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-		s := <-c
+		sig := waitShutdownSignal()
 
-		log.Printf("shutdown by signal: %v", s)
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		log.Printf("shutdown by signal: %v", sig)
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 		defer cancel()
 
 		if err := server.Shutdown(ctx); err != nil {
@@ -138,6 +245,54 @@ func runAsHTTPServer(cfg config.Server, s *solver.Solver) int {
 	return <-done
 }
 
+func runAsGRPCServer(cfg config.Config, s *solver.Reloadable) int {
+	shutdown := make(chan struct{})
+
+	server := grpc.NewServer()
+	multgenpb.RegisterMultgenServer(server, grpcapi.New(s, grpcapi.Options{
+		StreamInterval: cfg.Server.StreamInterval,
+		Shutdown:       shutdown,
+	}))
+
+	listener, err := net.Listen("tcp", cfg.Server.Addr)
+	if err != nil {
+		log.Printf("failed to listen on %s: %v", cfg.Server.Addr, err)
+		return 1
+	}
+
+	done := make(chan int, 1)
+	go func() {
+		defer close(done)
+
+		sig := waitShutdownSignal()
+		log.Printf("shutdown by signal: %v", sig)
+
+		close(shutdown)
+		stopped := make(chan struct{})
+		go func() {
+			server.GracefulStop()
+			close(stopped)
+		}()
+
+		tm := time.NewTimer(shutdownTimeout)
+		select {
+		case <-stopped:
+		case <-tm.C:
+			log.Println("graceful shutdown timeout")
+			server.Stop()
+			done <- 1
+		}
+	}()
+
+	log.Printf("grpc server listens on %v", cfg.Server.Addr)
+	if err := server.Serve(listener); err != nil {
+		log.Printf("grpc server fail: %v", err)
+		return 1
+	}
+
+	return <-done
+}
+
 func runAsCLI(in io.Reader, out io.Writer, s *solver.Solver) int {
 	var n int
 	if _, err := fmt.Fscan(in, &n); err != nil {
@@ -147,10 +302,13 @@ func runAsCLI(in io.Reader, out io.Writer, s *solver.Solver) int {
 
 	w := bufio.NewWriter(out)
 
+	// один поток — последовательность воспроизводима по seed
+	st := s.NewStream()
+
 	for i := 0; i < n; i++ {
-		multiplier := s.Solve()
+		multiplier := st.Solve()
 		b := w.AvailableBuffer()
-		b = strconv.AppendFloat(b, multiplier, 'g', -1, 64)
+		b = format.AppendMultiplier(b, multiplier, s.Precision())
 		b = append(b, '\n')
 		w.Write(b) // skip the write error check for performance; check it on flush
 	}
@@ -1,9 +1,11 @@
 package multgen_test
 
 import (
+	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"runtime"
 	"testing"
 	"time"
 
@@ -66,25 +68,32 @@ func BenchmarkHTTPServer(b *testing.B) {
 			b.Run("parallel", func(b *testing.B) {
 				for _, name := range []string{withoutKeepAlive, withKeepAlive} {
 					b.Run(name, func(b *testing.B) {
-						addr, stopServer := tb.startServer(b)
-						defer stopServer()
+						// throughput scaling with GOMAXPROCS (server and clients share the processes)
+						for _, procs := range gomaxprocsList() {
+							b.Run(fmt.Sprintf("procs=%d", procs), func(b *testing.B) {
+								defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(procs))
 
-						url := "http://" + addr + "/get"
+								addr, stopServer := tb.startServer(b)
+								defer stopServer()
 
-						b.ResetTimer()
-						b.ReportAllocs()
+								url := "http://" + addr + "/get"
 
-						testutils.AddRPSMetricToBenchmark(b, func() {
-							b.RunParallel(func(pb *testing.PB) {
+								b.ResetTimer()
+								b.ReportAllocs()
 
-								// always use a fast client to reduce the pressure on the benchmark
-								client := newFastHTTPClient(b, name == withKeepAlive)
+								testutils.AddRPSMetricToBenchmark(b, func() {
+									b.RunParallel(func(pb *testing.PB) {
 
-								for pb.Next() {
-									client.Get(url)
-								}
+										// always use a fast client to reduce the pressure on the benchmark
+										client := newFastHTTPClient(b, name == withKeepAlive)
+
+										for pb.Next() {
+											client.Get(url)
+										}
+									})
+								})
 							})
-						})
+						}
 					})
 				}
 			})
@@ -92,15 +101,35 @@ func BenchmarkHTTPServer(b *testing.B) {
 	}
 }
 
-func startHTTPServer(b *testing.B) (addr string, stopServer func()) {
-	s, err := solver.New(solver.DefaultConfig())
+// gomaxprocsList returns 1, 2, 4, ... up to runtime.NumCPU() (inclusive).
+func gomaxprocsList() []int {
+	var list []int
+	n := runtime.NumCPU()
+	for p := 1; p < n; p *= 2 {
+		list = append(list, p)
+	}
+	return append(list, n)
+}
+
+// newBenchSolver returns a solver that consumes random numbers on every Solve,
+// so the benchmarks include the cost of the per-goroutine random streams.
+func newBenchSolver(b *testing.B) *solver.Solver {
+	cfg := solver.DefaultConfig()
+	cfg.Algorithm = "pareto1"
+	cfg.RTP = 0.95
+	s, err := solver.New(cfg)
 	be.Err(b, err, nil)
+	return s
+}
+
+func startHTTPServer(b *testing.B) (addr string, stopServer func()) {
+	s := newBenchSolver(b)
 
 	listener, err := net.Listen("tcp", "127.0.0.1:0")
 	be.Err(b, err, nil)
 
 	server := &http.Server{
-		Handler: api.New(s),
+		Handler: api.New(s, api.Options{}),
 	}
 
 	go func() {
@@ -138,9 +167,8 @@ func (c *httpClient) Get(url string) {
 }
 
 func startFastHTTPServer(b *testing.B) (addr string, closeServer func()) {
-	s, err := solver.New(solver.DefaultConfig())
-	be.Err(b, err, nil)
-	handler := fastapi.New(s)
+	s := newBenchSolver(b)
+	handler := fastapi.New(s, fastapi.Options{})
 
 	listener, err := net.Listen("tcp", "127.0.0.1:0")
 	be.Err(b, err, nil)
@@ -1,12 +1,17 @@
 package config
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/aaa2ppp/multgen/internal/api/override"
 	"github.com/aaa2ppp/multgen/internal/solver"
 )
 
@@ -19,15 +24,39 @@ type Config struct {
 
 	Server Server
 	Solver solver.Config
+
+	// Именованные профили солвера для /games/{id}/... (только из файла
+	// конфигурации). Незаданные поля профиля берутся из Solver, кроме Seed:
+	// у каждого профиля своё зерно.
+	Games map[string]solver.Config
 }
 
 type Server struct {
-	Addr     string
-	FastHTTP bool
+	Addr       string
+	FastHTTP   bool
+	GRPC       bool // gRPC вместо HTTP (см. internal/api/grpc)
+	BatchLimit int  // максимальное n в /get?n=K (0 - по умолчанию)
+
+	StreamInterval time.Duration // интервал между событиями /stream (0 - по умолчанию)
+
+	Metrics bool // телеметрия и /metrics в формате Prometheus
+	Stats   bool // статистика выданных мультипликаторов на /stats
+
+	AdminToken string // токен для /admin/config (пусто - смена конфигурации на лету выключена)
+
+	Overrides override.Policy // допустимые /get?rtp=&algo=&alpha= (по умолчанию запрещены)
 }
 
 type Solver = solver.Config
 
+// Redacted возвращает копию конфигурации без секретов — для лога.
+func (c Config) Redacted() Config {
+	if c.Server.AdminToken != "" {
+		c.Server.AdminToken = "***"
+	}
+	return c
+}
+
 func algorithmsHelp(msg string, algos []solver.Algorithm) string {
 	var buf strings.Builder
 	buf.WriteString(msg)
@@ -45,63 +74,221 @@ func algorithmsHelp(msg string, algos []solver.Algorithm) string {
 	return buf.String()
 }
 
-func MustLoad(tune Config) Config {
+// EnvPrefix — префикс переменных окружения: MULTGEN_RTP для -rtp,
+// MULTGEN_STREAM_INTERVAL для -stream-interval и т.д.
+const EnvPrefix = "MULTGEN_"
+
+// options — значения флагов, которых нет в Config.
+type options struct {
+	help       bool
+	configPath string
+	rtp        float64 // входной RTP (см. Config.IgnoreInputRTP)
+}
+
+// newFlagSet описывает флаги, привязанные к полям c: значения по умолчанию
+// берутся из c, Parse и Set пишут прямо в c.
+func newFlagSet(c *Config, o *options) *flag.FlagSet {
+	flags := flag.NewFlagSet("multgen", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+
+	flags.BoolVar(&o.help, "help", false, "show usage help")
+	flags.StringVar(&o.configPath, "config", "", "config file (YAML, JSON or TOML by extension) with flag names as keys,"+
+		"\nsee also "+EnvPrefix+"<FLAG> environment variables; precedence: flags > env > file")
+
+	flags.BoolVar(&c.CLIMode, "cli", c.CLIMode, "cli mode:"+
+		"\n- http server does not start;"+
+		"\n- read one int N (sequence length) from stdin;"+
+		"\n- write N multipliers to stdout")
+
+	// Server flags
+	flags.StringVar(&c.Server.Addr, "http", c.Server.Addr, "http server address")
+	flags.BoolVar(&c.Server.FastHTTP, "fast", c.Server.FastHTTP, "use fasthttp instead of net/http")
+	flags.BoolVar(&c.Server.GRPC, "grpc", c.Server.GRPC, "serve gRPC (Get, Stream, Play) on the -http address instead of HTTP")
+	flags.IntVar(&c.Server.BatchLimit, "batch-limit", c.Server.BatchLimit, "max n in /get?n=K batch requests (0 - default)")
+	flags.BoolVar(&c.Server.Metrics, "metrics", c.Server.Metrics, "serve Prometheus metrics on /metrics")
+	flags.BoolVar(&c.Server.Stats, "stats", c.Server.Stats, "serve multiplier statistics on /stats")
+	flags.StringVar(&c.Server.AdminToken, "admin-token", c.Server.AdminToken, "bearer token for GET/PUT /admin/config (empty - disabled)")
+	flags.DurationVar(&c.Server.StreamInterval, "stream-interval", c.Server.StreamInterval, "default interval between /stream events and gRPC Stream messages (0 - default, not fasthttp)")
+	flags.Var(&c.Server.Overrides.RTP, "override-rtp", "allowed /get?rtp= values as min:max (empty - rtp override disabled)")
+	flags.Var(&c.Server.Overrides.Algorithms, "override-algos", "allowed /get?algo= values, comma separated (empty - algo override disabled)")
+	flags.Var(&c.Server.Overrides.Alpha, "override-alpha", "allowed /get?alpha= values as min:max (empty - alpha override disabled)")
+	flags.IntVar(&c.Server.Overrides.CacheSize, "override-cache", c.Server.Overrides.CacheSize, fmt.Sprintf("max cached solvers for /get overrides (0 - %d)", override.DefaultCacheSize))
+
+	// Solver flags
+	flags.Float64Var(&o.rtp, "rtp", 0, "rtp must be in (0, 1] (required)")
+	flags.StringVar(&c.Solver.Algorithm, "algo", c.Solver.Algorithm, algorithmsHelp("algorithm for generating multipliers", solver.Algorithms()))
+	flags.Float64Var(&c.Solver.Alpha, "alpha", c.Solver.Alpha, "alpha must be >= 1")
+	flags.BoolVar(&c.Solver.AddDelta, "d", c.Solver.AddDelta, "add delta to mulipliers (not with -precision)")
+	flags.Uint64Var(&c.Solver.Seed, "seed", c.Solver.Seed, "random seed to reproduce a sequence (0 - random)")
+	flags.Float64Var(&c.Solver.Min, "min", c.Solver.Min, "min multiplier, must be >= 1")
+	flags.Float64Var(&c.Solver.Max, "max", c.Solver.Max, "max multiplier, must be >= min")
+	flags.IntVar(&c.Solver.Precision, "precision", c.Solver.Precision, fmt.Sprintf("decimal places in multipliers, in [-1, %d] (-1 - no rounding)", solver.MaxPrecision))
+	flags.StringVar(&c.Solver.DistFile, "dist-file", c.Solver.DistFile, `distribution table for algorithm "table" (CSV or JSON)`)
+	flags.StringVar((*string)(&c.Solver.Rounding), "rounding", string(c.Solver.Rounding), "rounding mode for -precision: floor (house-favourable), nearest, ceil")
+
+	return flags
+}
+
+// envName возвращает имя переменной окружения для флага: stream-interval -> MULTGEN_STREAM_INTERVAL.
+func envName(flagName string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// Load собирает конфигурацию из tune, файла конфигурации, переменных
+// окружения и флагов args (без имени программы). Приоритет: флаги >
+// окружение > файл > tune. Путь к файлу задаётся -config или MULTGEN_CONFIG
+// и открывается в fsys. Ошибки возвращаются, а не завершают процесс;
+// на -help возвращается flag.ErrHelp.
+func Load(tune Config, args []string, lookupEnv func(string) (string, bool), fsys fs.FS) (Config, error) {
+	// первый проход: -help, -config и синтаксис флагов
 	var (
-		help = flag.Bool("help", false, "show usage help")
-
-		cliMode = flag.Bool("cli", tune.CLIMode, "cli mode:"+
-			"\n- http server does not start;"+
-			"\n- read one int N (sequence length) from stdin;"+
-			"\n- write N multipliers to stdout")
-
-		// Server flags
-		serverAddr = flag.String("http", tune.Server.Addr, "http server address")
-		fastHTTP   = flag.Bool("fast", tune.Server.FastHTTP, "use fasthttp instead of net/http")
-
-		// Solver flags
-		rtp       = flag.Float64("rtp", 0, "rtp must be in (0, 1] (required)")
-		algorithm = flag.String("algo", tune.Solver.Algorithm, algorithmsHelp("algorithm for generating multipliers", solver.Algorithms))
-		alpha     = flag.Float64("alpha", tune.Solver.Alpha, "alpha must be >= 1")
-		addDelta  = flag.Bool("d", tune.Solver.AddDelta, "add delta to mulipliers")
+		pre  = tune
+		opts options
 	)
+	if err := newFlagSet(&pre, &opts).Parse(args); err != nil {
+		return Config{}, err
+	}
+	if opts.help {
+		return Config{}, flag.ErrHelp
+	}
+
+	path := opts.configPath
+	if path == "" {
+		path, _ = lookupEnv(envName("config"))
+	}
 
-	flag.Parse()
+	cfg := tune
+	opts = options{}
+	flags := newFlagSet(&cfg, &opts)
 
-	if *help {
-		fmt.Fprint(os.Stderr, "Usage: multgen [options] -rtp=<value>\nOptions:\n")
-		flag.PrintDefaults()
-		os.Exit(0)
+	var games map[string]any
+	if path != "" {
+		var err error
+		if games, err = loadFile(flags, fsys, path); err != nil {
+			return Config{}, err
+		}
 	}
 
-	if *rtp == 0 {
-		fmt.Fprintln(os.Stderr, "rtp is required")
-		flag.PrintDefaults()
-		os.Exit(1)
+	var errs []error
+	flags.VisitAll(func(f *flag.Flag) {
+		if f.Name == "help" || f.Name == "config" {
+			return
+		}
+		if v, ok := lookupEnv(envName(f.Name)); ok {
+			if err := f.Value.Set(v); err != nil {
+				errs = append(errs, fmt.Errorf("%s: invalid value %q: %w", envName(f.Name), v, err))
+			}
+		}
+	})
+	if err := errors.Join(errs...); err != nil {
+		return Config{}, err
 	}
 
-	if !tune.IgnoreInputRTP && !(0 < *rtp && *rtp <= 1) {
-		fmt.Fprintf(os.Stderr, "rtp must be in (0, 1], got %v\n", *rtp)
-		flag.PrintDefaults()
-		os.Exit(1)
+	if err := flags.Parse(args); err != nil {
+		return Config{}, err
 	}
 
-	if !(*alpha >= 1) {
-		fmt.Fprintf(os.Stderr, "alpha must be >= 1, got %v", *alpha)
-		flag.PrintDefaults()
-		os.Exit(1)
+	if err := opts.validate(tune.IgnoreInputRTP, cfg); err != nil {
+		return Config{}, err
 	}
 
-	tune.CLIMode = *cliMode
+	if !tune.IgnoreInputRTP {
+		cfg.Solver.RTP = opts.rtp
+	}
 
-	tune.Server.Addr = *serverAddr
-	tune.Server.FastHTTP = *fastHTTP
+	if games != nil {
+		var err error
+		if cfg.Games, err = decodeGames(games, cfg.Solver); err != nil {
+			return Config{}, fmt.Errorf("%s: %w", path, err)
+		}
+	}
 
-	if !tune.IgnoreInputRTP {
-		tune.Solver.RTP = *rtp
+	if err := checkGRPC(cfg); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+// checkGRPC отклоняет в режиме -grpc возможности, которые есть только у
+// HTTP API, чтобы они не игнорировались молча.
+func checkGRPC(c Config) error {
+	if !c.Server.GRPC || c.CLIMode {
+		return nil
+	}
+
+	var httpOnly []string
+	if c.Server.Metrics {
+		httpOnly = append(httpOnly, "metrics")
+	}
+	if c.Server.Stats {
+		httpOnly = append(httpOnly, "stats")
+	}
+	if c.Server.AdminToken != "" {
+		httpOnly = append(httpOnly, "admin-token")
+	}
+	if c.Server.Overrides.Enabled() {
+		httpOnly = append(httpOnly, "override-*")
+	}
+	if len(c.Games) > 0 {
+		httpOnly = append(httpOnly, "games")
+	}
+	if len(httpOnly) > 0 {
+		return fmt.Errorf("%s not supported with grpc", strings.Join(httpOnly, ", "))
+	}
+	return nil
+}
+
+func (o options) validate(ignoreInputRTP bool, c Config) error {
+	var errs []error
+
+	if o.rtp == 0 {
+		errs = append(errs, errors.New("rtp is required"))
+	} else if !ignoreInputRTP && !(0 < o.rtp && o.rtp <= 1) {
+		errs = append(errs, fmt.Errorf("rtp must be in (0, 1], got %v", o.rtp))
+	}
+
+	if !(c.Solver.Alpha >= 1) {
+		errs = append(errs, fmt.Errorf("alpha must be >= 1, got %v", c.Solver.Alpha))
+	}
+
+	if !(1 <= c.Solver.Min && c.Solver.Min <= c.Solver.Max) {
+		errs = append(errs, fmt.Errorf("min and max multipliers must satisfy 1 <= min <= max, got min=%v max=%v", c.Solver.Min, c.Solver.Max))
+	}
+
+	if err := c.Server.Overrides.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// osFS открывает файлы по путям ОС как есть (абсолютные и относительно
+// рабочего каталога), в отличие от os.DirFS.
+type osFS struct{}
+
+func (osFS) Open(name string) (fs.File, error)    { return os.Open(name) }
+func (osFS) ReadFile(name string) ([]byte, error) { return os.ReadFile(name) }
+
+// MustLoad — Load из аргументов командной строки, окружения и файловой
+// системы процесса. При ошибке печатает её и справку и завершает процесс.
+func MustLoad(tune Config) Config {
+	cfg, err := Load(tune, os.Args[1:], os.LookupEnv, osFS{})
+	if err == nil {
+		return cfg
+	}
+
+	code := 1
+	if errors.Is(err, flag.ErrHelp) {
+		code = 0
+	} else {
+		fmt.Fprintln(os.Stderr, err)
 	}
-	tune.Solver.Algorithm = *algorithm
-	tune.Solver.Alpha = *alpha
-	tune.Solver.AddDelta = *addDelta
 
+	fmt.Fprint(os.Stderr, "Usage: multgen [options] -rtp=<value>\nOptions:\n")
+	flags := newFlagSet(&tune, &options{})
+	flags.SetOutput(os.Stderr)
+	flags.PrintDefaults()
+	os.Exit(code)
 	return tune
 }
@@ -0,0 +1,209 @@
+package config_test
+
+import (
+	"flag"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/aaa2ppp/be"
+
+	"github.com/aaa2ppp/multgen/internal/api/override"
+	"github.com/aaa2ppp/multgen/internal/config"
+	"github.com/aaa2ppp/multgen/internal/solver"
+)
+
+var tune = config.Config{
+	Server: config.Server{Addr: ":8080"},
+	Solver: solver.DefaultConfig(),
+}
+
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		v, ok := vars[name]
+		return v, ok
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	fsys := fstest.MapFS{
+		"multgen.yaml": {Data: []byte("rtp: 0.8\nalgo: paretoT\nalpha: 1.5\nhttp: \":9000\"\nstream-interval: 500ms\n")},
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+		want func(*config.Config)
+	}{
+		{
+			name: "flags only",
+			args: []string{"-rtp=0.9"},
+			want: func(c *config.Config) { c.Solver.RTP = 0.9 },
+		},
+		{
+			name: "file over tune",
+			args: []string{"-config=multgen.yaml"},
+			want: func(c *config.Config) {
+				c.Solver.RTP = 0.8
+				c.Solver.Algorithm = "paretoT"
+				c.Solver.Alpha = 1.5
+				c.Server.Addr = ":9000"
+				c.Server.StreamInterval = 500 * time.Millisecond
+			},
+		},
+		{
+			name: "env over file",
+			args: []string{"-config=multgen.yaml"},
+			env:  map[string]string{"MULTGEN_ALPHA": "2", "MULTGEN_STREAM_INTERVAL": "1s"},
+			want: func(c *config.Config) {
+				c.Solver.RTP = 0.8
+				c.Solver.Algorithm = "paretoT"
+				c.Solver.Alpha = 2
+				c.Server.Addr = ":9000"
+				c.Server.StreamInterval = time.Second
+			},
+		},
+		{
+			name: "override policy",
+			args: []string{"-rtp=0.9", "-override-rtp=0.8:1", "-override-algos=crash,max"},
+			env:  map[string]string{"MULTGEN_OVERRIDE_ALPHA": "1:3"},
+			want: func(c *config.Config) {
+				c.Solver.RTP = 0.9
+				c.Server.Overrides = override.Policy{
+					RTP:        override.Range{Min: 0.8, Max: 1},
+					Algorithms: override.List{"crash", "max"},
+					Alpha:      override.Range{Min: 1, Max: 3},
+				}
+			},
+		},
+		{
+			name: "flags over env",
+			args: []string{"-rtp=0.95", "-alpha=3"},
+			env:  map[string]string{"MULTGEN_CONFIG": "multgen.yaml", "MULTGEN_ALPHA": "2", "MULTGEN_FAST": "true"},
+			want: func(c *config.Config) {
+				c.Solver.RTP = 0.95
+				c.Solver.Algorithm = "paretoT"
+				c.Solver.Alpha = 3
+				c.Server.Addr = ":9000"
+				c.Server.FastHTTP = true
+				c.Server.StreamInterval = 500 * time.Millisecond
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := tune
+			tt.want(&want)
+
+			got, err := config.Load(tune, tt.args, env(tt.env), fsys)
+			be.Err(be.Require(t), err, nil)
+			be.Equal(t, got, want)
+		})
+	}
+}
+
+func TestLoadFormats(t *testing.T) {
+	fsys := fstest.MapFS{
+		"c.yaml": {Data: []byte("rtp: 0.9\nseed: 18446744073709551615\nd: true\nrounding: nearest\n")},
+		"c.json": {Data: []byte(`{"rtp": 0.9, "seed": 18446744073709551615, "d": true, "rounding": "nearest"}`)},
+		"c.toml": {Data: []byte("rtp = 0.9\nseed = \"18446744073709551615\"\nd = true\nrounding = \"nearest\"\n")},
+	}
+
+	want := tune
+	want.Solver.RTP = 0.9
+	want.Solver.Seed = 1<<64 - 1
+	want.Solver.AddDelta = true
+	want.Solver.Rounding = solver.RoundNearest
+
+	for name := range fsys {
+		t.Run(name, func(t *testing.T) {
+			got, err := config.Load(tune, []string{"-config=" + name}, env(nil), fsys)
+			be.Err(be.Require(t), err, nil)
+			be.Equal(t, got, want)
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	fsys := fstest.MapFS{
+		"unknown.yaml": {Data: []byte("rtp: 0.9\nfoo: 1\n")},
+		"nested.json":  {Data: []byte(`{"rtp": 0.9, "http": {"addr": ":1"}}`)},
+		"bad.ini":      {Data: []byte("rtp=0.9")},
+		"alpha.toml":   {Data: []byte("rtp = 0.9\nalpha = \"x\"\n")},
+		"games.yaml":   {Data: []byte("games:\n  a: {rtp: 0.95}\n")},
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+		want any
+	}{
+		{"help", []string{"-help"}, nil, flag.ErrHelp},
+		{"no rtp", nil, nil, "rtp is required"},
+		{"rtp range", []string{"-rtp=2"}, nil, "rtp must be in (0, 1]"},
+		{"bad flag", []string{"-rtp=0.9", "-nope"}, nil, "flag provided but not defined"},
+		{"bad env", []string{"-rtp=0.9"}, map[string]string{"MULTGEN_BATCH_LIMIT": "many"}, "MULTGEN_BATCH_LIMIT"},
+		{"min max", []string{"-rtp=0.9", "-min=5", "-max=2"}, nil, "1 <= min <= max"},
+		{"grpc", []string{"-rtp=0.9", "-grpc", "-metrics", "-override-rtp=0.9:1"}, nil, "metrics, override-* not supported with grpc"},
+		{"grpc games", []string{"-rtp=0.9", "-grpc", "-config=games.yaml"}, nil, "games not supported with grpc"},
+		{"override algo", []string{"-rtp=0.9", "-override-algos=crash,crsh"}, nil, `unknown override algorithm "crsh"`},
+		{"no file", []string{"-config=missing.yaml"}, nil, "missing.yaml"},
+		{"unknown key", []string{"-config=unknown.yaml"}, nil, `unknown option "foo"`},
+		{"nested", []string{"-config=nested.json"}, nil, "want a scalar value"},
+		{"format", []string{"-config=bad.ini"}, nil, "unknown config format"},
+		{"bad value", []string{"-config=alpha.toml"}, nil, "alpha: invalid value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := config.Load(tune, tt.args, env(tt.env), fsys)
+			be.Err(t, err, tt.want)
+		})
+	}
+}
+
+func TestLoadGames(t *testing.T) {
+	fsys := fstest.MapFS{
+		"games.yaml": {Data: []byte("rtp: 0.9\nseed: 7\nmax: 100\ngames:\n  crash95: {rtp: 0.95, algorithm: crash}\n  cap: {max: 50, seed: 3}\n")},
+		"games.json": {Data: []byte(`{"rtp": 0.9, "games": {"x/y": {}}}`)},
+		"rtp.toml":   {Data: []byte("rtp = 0.9\n[games.bad]\nrtp = 2\n")},
+		"field.json": {Data: []byte(`{"rtp": 0.9, "games": {"a": {"rtpp": 0.5}}}`)},
+	}
+
+	got, err := config.Load(tune, []string{"-config=games.yaml", "-alpha=2"}, env(nil), fsys)
+	be.Err(be.Require(t), err, nil)
+
+	// профили наследуют итоговую конфигурацию солвера, кроме зерна
+	base := got.Solver
+	base.Seed = 0
+	crash, capped := base, base
+	crash.RTP = 0.95
+	crash.Algorithm = "crash"
+	capped.Max = 50
+	capped.Seed = 3
+	be.Equal(t, got.Games, map[string]solver.Config{"crash95": crash, "cap": capped})
+	be.Equal(t, got.Solver.Alpha, 2.0)
+	be.Equal(t, got.Solver.Seed, uint64(7))
+
+	for name, want := range map[string]string{
+		"games.json": `invalid game id "x/y"`,
+		"rtp.toml":   "games.bad: rtp value is incorrect",
+		"field.json": `unknown field "rtpp"`,
+	} {
+		_, err := config.Load(tune, []string{"-config=" + name}, env(nil), fsys)
+		be.Err(t, err, want)
+	}
+}
+
+func TestLoadIgnoreInputRTP(t *testing.T) {
+	tune := tune
+	tune.IgnoreInputRTP = true
+	tune.Solver.RTP = 0.97
+
+	got, err := config.Load(tune, []string{"-rtp=5"}, env(nil), fstest.MapFS{})
+	be.Err(be.Require(t), err, nil)
+	be.Equal(t, got.Solver.RTP, 0.97)
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+
+	"github.com/aaa2ppp/multgen/internal/solver"
+)
+
+// loadFile читает файл конфигурации и применяет его к флагам flags.
+// Файл — плоский набор "имя флага: значение", формат по расширению:
+//
+//	# multgen.yaml
+//	rtp: 0.95
+//	algo: paretoT
+//	alpha: 1.2
+//	http: ":8080"
+//	stream-interval: 500ms
+//
+// Единственный вложенный ключ — games, профили солвера по id (см. Config.Games):
+//
+//	games:
+//	  crash95: {rtp: 0.95, algorithm: crash}
+//	  table:   {algorithm: table, dist_file: dist.csv}
+//
+// Профили возвращаются как есть, их разбирает decodeGames.
+func loadFile(flags *flag.FlagSet, fsys fs.FS, path string) (games map[string]any, err error) {
+	data, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := parseFile(path, data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if v, ok := values[gamesKey]; ok {
+		if games, ok = v.(map[string]any); !ok {
+			return nil, fmt.Errorf("%s: %s: want a map of profiles by id, got %T", path, gamesKey, v)
+		}
+		delete(values, gamesKey)
+	}
+
+	// порядок ошибок не должен зависеть от порядка обхода map
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		f := flags.Lookup(key)
+		if f == nil || key == "help" || key == "config" {
+			return nil, fmt.Errorf("%s: unknown option %q", path, key)
+		}
+
+		v, err := scalar(values[key])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", path, key, err)
+		}
+
+		if err := f.Value.Set(v); err != nil {
+			return nil, fmt.Errorf("%s: %s: invalid value %q: %w", path, key, v, err)
+		}
+	}
+
+	return games, nil
+}
+
+func parseFile(path string, data []byte) (map[string]any, error) {
+	var values map[string]any
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &values); err != nil {
+			return nil, err
+		}
+	case ".json":
+		// UseNumber: seed не должен терять точность в float64
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if err := dec.Decode(&values); err != nil {
+			return nil, err
+		}
+	case ".toml":
+		if err := toml.Unmarshal(data, &values); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown config format %q: want .yaml, .yml, .json or .toml", ext)
+	}
+
+	return values, nil
+}
+
+// gamesKey — ключ профилей солвера в файле конфигурации.
+const gamesKey = "games"
+
+// validGameID — id профиля попадает в путь /games/{id}/..., поэтому без
+// спецсимволов.
+var validGameID = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// decodeGames собирает профили солвера: поля каждого профиля (ключи — как
+// в JSON solver.Config) накладываются на base со сброшенным зерном.
+func decodeGames(games map[string]any, base solver.Config) (map[string]solver.Config, error) {
+	ids := make([]string, 0, len(games))
+	for id := range games {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	base.Seed = 0
+	cfgs := make(map[string]solver.Config, len(games))
+	for _, id := range ids {
+		if !validGameID.MatchString(id) {
+			return nil, fmt.Errorf("%s: invalid game id %q: want letters, digits, '_', '-' or '.'", gamesKey, id)
+		}
+
+		// через JSON, чтобы профиль читался одинаково из всех форматов
+		data, err := json.Marshal(games[id])
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", gamesKey, id, err)
+		}
+
+		cfg := base
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&cfg); err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", gamesKey, id, err)
+		}
+
+		if err := cfg.Validate(); err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", gamesKey, id, err)
+		}
+
+		cfgs[id] = cfg
+	}
+
+	return cfgs, nil
+}
+
+// scalar переводит значение из файла в строку для flag.Value.Set.
+func scalar(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case bool, int, int64, uint64, float64, json.Number:
+		return fmt.Sprint(v), nil
+	}
+	return "", fmt.Errorf("want a scalar value, got %T", v)
+}
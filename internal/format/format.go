@@ -0,0 +1,13 @@
+// Package format — единый формат вывода мультипликаторов для CLI и HTTP API.
+package format
+
+import "strconv"
+
+// AppendMultiplier дописывает мультипликатор в dst: с precision знаками
+// после точки или, если precision < 0, в кратчайшем виде.
+func AppendMultiplier(dst []byte, m float64, precision int) []byte {
+	if precision < 0 {
+		return strconv.AppendFloat(dst, m, 'g', -1, 64)
+	}
+	return strconv.AppendFloat(dst, m, 'f', precision, 64)
+}
@@ -0,0 +1,125 @@
+// Package game описывает раунд игры поверх мультипликатора солвера.
+//
+// Модель как в crash-играх (и в TheoreticalRTP солвера): игрок ставит bet
+// и выбирает x; если выпавший мультипликатор m > x, он получает bet·x,
+// иначе теряет ставку. RTP = сумма выплат / сумма ставок.
+package game
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/aaa2ppp/multgen/internal/format"
+)
+
+// Bet — ставка игрока на раунд.
+type Bet struct {
+	Bet float64 `json:"bet"` // размер ставки
+	X   float64 `json:"x"`   // мультипликатор, который игрок "забирает"
+}
+
+func (b Bet) Validate() error {
+	var errs []error
+
+	if !(b.Bet > 0) || math.IsInf(b.Bet, 0) {
+		errs = append(errs, fmt.Errorf("bet must be finite and > 0, got %g", b.Bet))
+	}
+
+	if !(b.X >= 1) || math.IsInf(b.X, 0) {
+		errs = append(errs, fmt.Errorf("x must be finite and >= 1, got %g", b.X))
+	}
+
+	return errors.Join(errs...)
+}
+
+// PlayRequest — тело запроса /play: {"bet":1,"target":2}.
+type PlayRequest struct {
+	Bet    float64 `json:"bet"`
+	Target float64 `json:"target"`
+}
+
+// Outcome — итог раунда.
+type Outcome struct {
+	Multiplier float64 `json:"multiplier"` // выпавший мультипликатор
+	Win        bool    `json:"win"`        // m > x
+	Payout     float64 `json:"payout"`     // bet·x при выигрыше, иначе 0
+	Precision  int     `json:"-"`          // знаков после точки в Multiplier (-1 - без округления)
+}
+
+// AppendJSON дописывает к dst итог в JSON, мультипликатор — с o.Precision
+// знаками после точки (как в /get).
+func (o Outcome) AppendJSON(dst []byte) []byte {
+	dst = append(dst, `{"multiplier":`...)
+	dst = format.AppendMultiplier(dst, o.Multiplier, o.Precision)
+	dst = append(dst, `,"win":`...)
+	dst = strconv.AppendBool(dst, o.Win)
+	dst = append(dst, `,"payout":`...)
+	dst = strconv.AppendFloat(dst, o.Payout, 'g', -1, 64)
+	dst = append(dst, '}')
+	return dst
+}
+
+// Settle рассчитывает итог ставки b при мультипликаторе m.
+func Settle(b Bet, m float64) Outcome {
+	if m > b.X {
+		return Outcome{Multiplier: m, Win: true, Payout: b.Bet * b.X}
+	}
+	return Outcome{Multiplier: m}
+}
+
+type Solver interface {
+	SolvePrecision() (float64, int)
+	Observe(bet float64, win bool, payout float64)
+}
+
+// Play разыгрывает ставку b на сервере: берёт мультипликатор у s,
+// рассчитывает итог и сообщает его солверу (статистика, feedback).
+func Play(s Solver, b Bet) Outcome {
+	m, prec := s.SolvePrecision()
+	o := Settle(b, m)
+	o.Precision = prec
+	s.Observe(b.Bet, o.Win, o.Payout)
+	return o
+}
+
+// Result — итог раунда в сессии.
+type Result struct {
+	Round uint64 `json:"round"` // номер раунда в сессии (с 1)
+	Outcome
+	RTP float64 `json:"rtp"` // фактический RTP сессии с учётом этого раунда
+}
+
+// Session — последовательность раундов одного игрока (например, одного
+// соединения) с накопленным фактическим RTP. Не безопасна для конкурентного
+// использования.
+type Session struct {
+	rounds uint64
+	bet    float64
+	payout float64
+}
+
+// Add учитывает в сессии итог o ставки b.
+func (s *Session) Add(b Bet, o Outcome) Result {
+	s.rounds++
+	s.bet += b.Bet
+	s.payout += o.Payout
+
+	return Result{
+		Round:   s.rounds,
+		Outcome: o,
+		RTP:     s.RTP(),
+	}
+}
+
+// Rounds возвращает число сыгранных раундов.
+func (s *Session) Rounds() uint64 { return s.rounds }
+
+// RTP возвращает фактический RTP сессии (0, если раундов ещё не было).
+func (s *Session) RTP() float64 {
+	if s.bet == 0 {
+		return 0
+	}
+	return s.payout / s.bet
+}
@@ -0,0 +1,17 @@
+package game
+
+import "time"
+
+// Пределы, общие для HTTP (net/http и fasthttp) и gRPC API.
+const (
+	// DefaultBatchLimit — предел n в /get?n=K, если он не задан в настройках API.
+	DefaultBatchLimit = 1000
+
+	// DefaultStreamInterval — интервал между событиями /stream и сообщениями
+	// gRPC Stream, если он не задан в настройках API.
+	DefaultStreamInterval = time.Second
+
+	// MinStreamInterval — нижняя граница интервала, чтобы один клиент не мог
+	// заставить сервер генерировать события без остановки.
+	MinStreamInterval = 10 * time.Millisecond
+)
@@ -0,0 +1,317 @@
+// Package metrics собирает телеметрию сервиса и отдаёт её в текстовом
+// формате Prometheus.
+//
+// Реализация без внешних зависимостей: все счётчики — атомики, набор
+// маршрутов и границы гистограмм задаются при старте, поэтому учёт запроса
+// не аллоцирует и не берёт блокировок. Методы безопасны на nil-получателе
+// (метрики выключены).
+package metrics
+
+import (
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aaa2ppp/multgen/internal/solver"
+)
+
+var (
+	// DurationBuckets — границы гистограммы времени ответа, секунды.
+	DurationBuckets = []float64{.0001, .00025, .0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+	// MultiplierBuckets — границы гистограммы выданных мультипликаторов.
+	MultiplierBuckets = []float64{1, 1.01, 1.1, 1.5, 2, 3, 5, 10, 20, 50, 100, 1000, 10000}
+)
+
+// maxCode — коды ответа HTTP в [0, maxCode).
+const maxCode = 600
+
+type Metrics struct {
+	mu     sync.Mutex // только для регистрации маршрутов
+	routes []*Route
+
+	multipliers *Histogram
+
+	rounds atomic.Uint64
+	wins   atomic.Uint64
+	bet    Float
+	payout Float
+}
+
+func New() *Metrics {
+	return &Metrics{multipliers: NewHistogram(MultiplierBuckets)}
+}
+
+// Route — счётчики одного маршрута.
+type Route struct {
+	name     string
+	codes    [maxCode]atomic.Uint64
+	duration *Histogram
+}
+
+// Route регистрирует маршрут name (или возвращает уже зарегистрированный).
+// Вызывается при построении обработчиков, не на каждый запрос.
+func (m *Metrics) Route(name string) *Route {
+	if m == nil {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, r := range m.routes {
+		if r.name == name {
+			return r
+		}
+	}
+
+	r := &Route{name: name, duration: NewHistogram(DurationBuckets)}
+	m.routes = append(m.routes, r)
+	return r
+}
+
+// Observe учитывает ответ с кодом code, обработанный за d.
+func (r *Route) Observe(code int, d time.Duration) {
+	if r == nil {
+		return
+	}
+	if 0 <= code && code < maxCode {
+		r.codes[code].Add(1)
+	}
+	r.duration.Observe(d.Seconds())
+}
+
+// ObserveMultiplier учитывает выданный мультипликатор.
+func (m *Metrics) ObserveMultiplier(v float64) {
+	if m == nil {
+		return
+	}
+	m.multipliers.Observe(v)
+}
+
+// ObserveRound учитывает итог раунда (см. Solver.Observe).
+func (m *Metrics) ObserveRound(bet float64, win bool, payout float64) {
+	if m == nil {
+		return
+	}
+	m.rounds.Add(1)
+	if win {
+		m.wins.Add(1)
+	}
+	m.bet.Add(bet)
+	m.payout.Add(payout)
+}
+
+// AppendText дописывает к dst все метрики в текстовом формате Prometheus.
+func (m *Metrics) AppendText(dst []byte) []byte {
+	if m == nil {
+		return dst
+	}
+
+	m.mu.Lock()
+	routes := slices.Clone(m.routes)
+	m.mu.Unlock()
+
+	dst = append(dst, "# HELP multgen_http_requests_total Number of HTTP requests by route and status code.\n"...)
+	dst = append(dst, "# TYPE multgen_http_requests_total counter\n"...)
+	for _, r := range routes {
+		for code := range r.codes {
+			n := r.codes[code].Load()
+			if n == 0 {
+				continue
+			}
+			dst = append(dst, `multgen_http_requests_total{route="`...)
+			dst = append(dst, r.name...)
+			dst = append(dst, `",code="`...)
+			dst = strconv.AppendInt(dst, int64(code), 10)
+			dst = append(dst, `"} `...)
+			dst = strconv.AppendUint(dst, n, 10)
+			dst = append(dst, '\n')
+		}
+	}
+
+	dst = append(dst, "# HELP multgen_http_request_duration_seconds HTTP request latency by route.\n"...)
+	dst = append(dst, "# TYPE multgen_http_request_duration_seconds histogram\n"...)
+	for _, r := range routes {
+		dst = r.duration.appendText(dst, "multgen_http_request_duration_seconds", `route="`+r.name+`"`)
+	}
+
+	dst = append(dst, "# HELP multgen_multiplier Served multipliers.\n"...)
+	dst = append(dst, "# TYPE multgen_multiplier histogram\n"...)
+	dst = m.multipliers.appendText(dst, "multgen_multiplier", "")
+
+	if rounds := m.rounds.Load(); rounds > 0 {
+		dst = appendSample(dst, "multgen_rounds_total", "counter", "Number of settled rounds.", float64(rounds))
+		dst = appendSample(dst, "multgen_rounds_won_total", "counter", "Number of settled rounds won by players.", float64(m.wins.Load()))
+		dst = appendSample(dst, "multgen_bet_total", "counter", "Sum of bets of settled rounds.", m.bet.Load())
+		dst = appendSample(dst, "multgen_payout_total", "counter", "Sum of payouts of settled rounds.", m.payout.Load())
+
+		var rtp float64
+		if bet := m.bet.Load(); bet > 0 {
+			rtp = m.payout.Load() / bet
+		}
+		dst = appendSample(dst, "multgen_realized_rtp", "gauge", "Realised RTP: payouts / bets of settled rounds.", rtp)
+	}
+
+	return dst
+}
+
+// WriteTo пишет метрики в w (см. AppendText).
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(m.AppendText(nil))
+	return int64(n), err
+}
+
+func appendSample(dst []byte, name, typ, help string, v float64) []byte {
+	dst = append(dst, "# HELP "...)
+	dst = append(dst, name...)
+	dst = append(dst, ' ')
+	dst = append(dst, help...)
+	dst = append(dst, "\n# TYPE "...)
+	dst = append(dst, name...)
+	dst = append(dst, ' ')
+	dst = append(dst, typ...)
+	dst = append(dst, '\n')
+	dst = append(dst, name...)
+	dst = append(dst, ' ')
+	dst = appendFloat(dst, v)
+	dst = append(dst, '\n')
+	return dst
+}
+
+func appendFloat(dst []byte, v float64) []byte {
+	switch {
+	case math.IsInf(v, 1):
+		return append(dst, "+Inf"...)
+	case math.IsInf(v, -1):
+		return append(dst, "-Inf"...)
+	case math.IsNaN(v):
+		return append(dst, "NaN"...)
+	}
+	return strconv.AppendFloat(dst, v, 'g', -1, 64)
+}
+
+// Float — float64 с атомарным сложением.
+type Float struct {
+	bits atomic.Uint64
+}
+
+func (f *Float) Add(v float64) {
+	for {
+		old := f.bits.Load()
+		if f.bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+v)) {
+			return
+		}
+	}
+}
+
+func (f *Float) Load() float64   { return math.Float64frombits(f.bits.Load()) }
+func (f *Float) Store(v float64) { f.bits.Store(math.Float64bits(v)) }
+
+// Histogram — гистограмма с фиксированными границами.
+type Histogram struct {
+	bounds []float64       // верхние границы (le), по возрастанию
+	counts []atomic.Uint64 // counts[i] — значения в (bounds[i-1], bounds[i]], последний — +Inf
+	sum    Float
+}
+
+func NewHistogram(bounds []float64) *Histogram {
+	return &Histogram{
+		bounds: bounds,
+		counts: make([]atomic.Uint64, len(bounds)+1),
+	}
+}
+
+func (h *Histogram) Observe(v float64) {
+	i, _ := slices.BinarySearch(h.bounds, v) // первая граница >= v
+	h.counts[i].Add(1)
+	h.sum.Add(v)
+}
+
+func (h *Histogram) appendText(dst []byte, name, labels string) []byte {
+	var total uint64
+	for i := range h.counts {
+		total += h.counts[i].Load()
+
+		dst = append(dst, name...)
+		dst = append(dst, "_bucket{"...)
+		if labels != "" {
+			dst = append(dst, labels...)
+			dst = append(dst, ',')
+		}
+		dst = append(dst, `le="`...)
+		if i < len(h.bounds) {
+			dst = appendFloat(dst, h.bounds[i])
+		} else {
+			dst = append(dst, "+Inf"...)
+		}
+		dst = append(dst, `"} `...)
+		dst = strconv.AppendUint(dst, total, 10)
+		dst = append(dst, '\n')
+	}
+
+	dst = appendSeries(dst, name, "_sum", labels)
+	dst = appendFloat(dst, h.sum.Load())
+	dst = append(dst, '\n')
+
+	dst = appendSeries(dst, name, "_count", labels)
+	dst = strconv.AppendUint(dst, total, 10)
+	dst = append(dst, '\n')
+
+	return dst
+}
+
+// appendSeries дописывает имя серии с метками: name+suffix{labels}.
+func appendSeries(dst []byte, name, suffix, labels string) []byte {
+	dst = append(dst, name...)
+	dst = append(dst, suffix...)
+	if labels != "" {
+		dst = append(dst, '{')
+		dst = append(dst, labels...)
+		dst = append(dst, '}')
+	}
+	return append(dst, ' ')
+}
+
+type Solver interface {
+	Solve() float64
+	SolvePrecision() (float64, int) // мультипликатор и его точность от одного солвера
+	Observe(bet float64, win bool, payout float64)
+	TheoreticalRTP(x float64) (float64, bool)
+	RoundStats() solver.RoundStats
+}
+
+type instrumented struct {
+	Solver
+	m *Metrics
+}
+
+func (s instrumented) Solve() float64 {
+	v := s.Solver.Solve()
+	s.m.ObserveMultiplier(v)
+	return v
+}
+
+func (s instrumented) SolvePrecision() (float64, int) {
+	v, prec := s.Solver.SolvePrecision()
+	s.m.ObserveMultiplier(v)
+	return v, prec
+}
+
+func (s instrumented) Observe(bet float64, win bool, payout float64) {
+	s.Solver.Observe(bet, win, payout)
+	s.m.ObserveRound(bet, win, payout)
+}
+
+// Instrument оборачивает солвер так, что выданные мультипликаторы и итоги
+// раундов попадают в метрики. Для nil-метрик возвращает s как есть.
+func (m *Metrics) Instrument(s Solver) Solver {
+	if m == nil {
+		return s
+	}
+	return instrumented{s, m}
+}
@@ -0,0 +1,165 @@
+package player
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+)
+
+// Observer — стратегия, которая учитывает исходы раундов. Observe вызывается
+// после каждого раунда: m — мультипликатор раунда, win — игрок выиграл (m > x).
+type Observer interface {
+	Observe(m float64, win bool)
+}
+
+// Staker — стратегия, которая меняет размер ставки: платёж и выигрыш раунда
+// умножаются на Stake.
+type Staker interface {
+	Stake() float64
+}
+
+// martingale удваивает x после проигрыша (anti - после выигрыша) и
+// возвращается к x0 после противоположного исхода или выхода за max.
+type martingale struct {
+	anti       bool
+	x0, x, max float64
+}
+
+func (s *martingale) X() float64 { return s.x }
+
+func (s *martingale) Observe(_ float64, win bool) {
+	if win == s.anti {
+		s.x *= 2
+	} else {
+		s.x = s.x0
+	}
+	if s.x > s.max {
+		s.x = s.x0
+	}
+}
+
+// martingaleBet держит x и удваивает ставку после проигрыша (anti - после
+// выигрыша); после противоположного исхода или n удвоений подряд ставка
+// возвращается к 1.
+type martingaleBet struct {
+	anti  bool
+	x     float64
+	n, k  int // предел удвоений и сделано удвоений
+	stake float64
+}
+
+func (s *martingaleBet) X() float64 { return s.x }
+
+func (s *martingaleBet) Stake() float64 { return s.stake }
+
+func (s *martingaleBet) Observe(_ float64, win bool) {
+	if win == s.anti && s.k < s.n {
+		s.stake *= 2
+		s.k++
+	} else {
+		s.stake, s.k = 1, 0
+	}
+}
+
+func parseMartingale(anti bool) func(args []float64, min, max float64) (Factory, error) {
+	return func(args []float64, min, max float64) (Factory, error) {
+		if err := wantArgs(args, 1); err != nil {
+			return nil, err
+		}
+		x0 := args[0]
+		if err := inRange("x0", x0, min, max); err != nil {
+			return nil, err
+		}
+		return func(*rand.Rand) Strategy {
+			return &martingale{anti: anti, x0: x0, x: x0, max: max}
+		}, nil
+	}
+}
+
+func parseMartingaleBet(anti bool) func(args []float64, min, max float64) (Factory, error) {
+	return func(args []float64, min, max float64) (Factory, error) {
+		if err := wantArgs(args, 2); err != nil {
+			return nil, err
+		}
+		x, n := args[0], args[1]
+		if err := inRange("x", x, min, max); err != nil {
+			return nil, err
+		}
+		if !(n >= 0 && n <= 64 && n == float64(int(n))) {
+			return nil, fmt.Errorf("n must be an integer in [0, 64], got %g", n)
+		}
+		return func(*rand.Rand) Strategy {
+			return &martingaleBet{anti: anti, x: x, n: int(n), stake: 1}
+		}, nil
+	}
+}
+
+// window выбирает x по последним k мультипликаторам (среднее, медиана или
+// максимум). Пока истории нет, x = min.
+type window struct {
+	stat     func(ms []float64) float64
+	ms       []float64 // кольцевой буфер
+	next     int
+	x        float64
+	min, max float64
+	sorted   []float64 // буфер для медианы
+}
+
+func (s *window) X() float64 { return s.x }
+
+func (s *window) Observe(m float64, _ bool) {
+	if len(s.ms) < cap(s.ms) {
+		s.ms = append(s.ms, m)
+	} else {
+		s.ms[s.next] = m
+		s.next = (s.next + 1) % len(s.ms)
+	}
+	s.x = clamp(s.stat(s.ms), s.min, s.max)
+}
+
+func (s *window) mean(ms []float64) float64 {
+	var sum float64
+	for _, m := range ms {
+		sum += m
+	}
+	return sum / float64(len(ms))
+}
+
+func (s *window) median(ms []float64) float64 {
+	s.sorted = append(s.sorted[:0], ms...)
+	slices.Sort(s.sorted)
+	n := len(s.sorted)
+	if n%2 == 1 {
+		return s.sorted[n/2]
+	}
+	return (s.sorted[n/2-1] + s.sorted[n/2]) / 2
+}
+
+func (s *window) maximum(ms []float64) float64 {
+	return slices.Max(ms)
+}
+
+func parseWindow(stat string) func(args []float64, min, max float64) (Factory, error) {
+	return func(args []float64, min, max float64) (Factory, error) {
+		if err := wantArgs(args, 1); err != nil {
+			return nil, err
+		}
+		k := args[0]
+		if !(k >= 1 && k <= 1e6 && k == float64(int(k))) {
+			return nil, errors.New("k must be an integer in [1, 1e6]")
+		}
+		return func(*rand.Rand) Strategy {
+			s := &window{ms: make([]float64, 0, int(k)), x: min, min: min, max: max}
+			switch stat {
+			case "mean":
+				s.stat = s.mean
+			case "median":
+				s.stat = s.median
+			default:
+				s.stat = s.maximum
+			}
+			return s
+		}, nil
+	}
+}
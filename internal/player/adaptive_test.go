@@ -0,0 +1,84 @@
+package player_test
+
+import (
+	"testing"
+
+	"github.com/aaa2ppp/be"
+
+	"github.com/aaa2ppp/multgen/internal/player"
+)
+
+// play разыгрывает раунды с мультипликаторами ms и возвращает x и ставку
+// игрока на каждом раунде.
+func play(t *testing.T, spec string, ms ...float64) (xs, stakes []float64) {
+	t.Helper()
+	f, err := player.Parse(spec, 1, 100)
+	be.Err(t, err, nil)
+
+	s := f(newRand())
+	o, ok := s.(player.Observer)
+	be.True(t, ok)
+	st, _ := s.(player.Staker)
+
+	for _, m := range ms {
+		x := s.X()
+		stake := 1.0
+		if st != nil {
+			stake = st.Stake()
+		}
+		xs = append(xs, x)
+		stakes = append(stakes, stake)
+		o.Observe(m, m > x)
+	}
+	return xs, stakes
+}
+
+func TestMartingale(t *testing.T) {
+	// проигрыш, проигрыш, выигрыш, проигрыш
+	xs, _ := play(t, "martingale:2", 1, 1, 10, 1, 1)
+	be.Equal(t, xs, []float64{2, 4, 8, 2, 4})
+
+	// x не выходит за max
+	xs, _ = play(t, "martingale:30", 1, 1, 1)
+	be.Equal(t, xs, []float64{30, 60, 30})
+}
+
+func TestAntiMartingale(t *testing.T) {
+	// выигрыш, выигрыш, проигрыш
+	xs, _ := play(t, "antimartingale:2", 100, 100, 1, 1)
+	be.Equal(t, xs, []float64{2, 4, 8, 2})
+}
+
+func TestMartingaleBet(t *testing.T) {
+	xs, stakes := play(t, "martingale-bet:2,2", 1, 1, 1, 1, 10, 1)
+	be.Equal(t, xs, []float64{2, 2, 2, 2, 2, 2})
+	be.Equal(t, stakes, []float64{1, 2, 4, 1, 2, 1})
+
+	_, stakes = play(t, "antimartingale-bet:2,3", 10, 10, 1, 10)
+	be.Equal(t, stakes, []float64{1, 2, 4, 1})
+}
+
+func TestWindow(t *testing.T) {
+	ms := []float64{3, 1, 8, 4, 2}
+
+	xs, _ := play(t, "window-mean:2", ms...)
+	be.Equal(t, xs, []float64{1, 3, 2, 4.5, 6})
+
+	xs, _ = play(t, "window-median:3", ms...)
+	be.Equal(t, xs, []float64{1, 3, 2, 3, 4})
+
+	xs, _ = play(t, "window-max:2", ms...)
+	be.Equal(t, xs, []float64{1, 3, 3, 8, 8})
+}
+
+func TestParse_AdaptiveErrors(t *testing.T) {
+	for spec, want := range map[string]string{
+		"martingale:0.5":       "x0 must be in",
+		"martingale-bet:2":     "want 2 parameter(s)",
+		"martingale-bet:2,1.5": "n must be an integer",
+		"window-mean:0":        "k must be an integer",
+	} {
+		_, err := player.Parse(spec, 1, 100)
+		be.Err(t, err, want)
+	}
+}
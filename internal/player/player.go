@@ -0,0 +1,283 @@
+// Package player — модели поведения игрока для bin/check: как игрок выбирает x
+// (мультипликатор, который он "забирает") на каждый раунд. Гипотезы —
+// cmd/check/q1.md.
+//
+// Стратегия задаётся строкой "имя:параметры", например "normal:5,1"
+// (см. Parse и Help). Каждый игрок получает собственный экземпляр стратегии
+// со своим генератором, поэтому стратегии с состоянием не мешают друг другу.
+package player
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// Strategy — стратегия одного игрока. Стратегии, зависящие от истории,
+// дополнительно реализуют Observer, а меняющие ставку - Staker.
+type Strategy interface {
+	// X возвращает x на очередной раунд, в [min, max].
+	X() float64
+}
+
+// Factory создаёт стратегию для одного игрока; r — генератор этого игрока.
+type Factory func(r *rand.Rand) Strategy
+
+// DefaultStrategy — нулевая гипотеза: игрок без стратегии.
+const DefaultStrategy = "uniform"
+
+type strategy struct {
+	name  string
+	usage string // параметры для справки
+	help  string
+
+	// parse проверяет параметры и строит фабрику; x ограничен [min, max].
+	parse func(args []float64, min, max float64) (Factory, error)
+}
+
+var strategies = []strategy{
+	{"uniform", "", "x ~ Uniform(min, max)", parseUniform},
+	{"fixed", "x", "всегда x", parseFixed},
+	{"normal", "mu,sigma", "x ~ Normal(mu, sigma), обрезанное до [min, max]", parseNormal},
+	{"exp", "mean", "x = min + Exp, среднее mean: чаще маленькие x, редко большие", parseExp},
+	{"gamma", "k,theta", "x = min + Gamma(k, theta), обрезанное до max", parseGamma},
+	{"beta", "a,b", "x = min + Beta(a, b)*(max-min): U-, J- или колоколообразное", parseBeta},
+	{"levels", "x1,x2,...", "случайный x из набора фиксированных уровней", parseLevels},
+	{"geom", "x0,r", "план x_i = x0*r^i; вышел за [min, max] - снова x0", parseGeom},
+	{"martingale", "x0", "x удваивается после проигрыша, после выигрыша - снова x0", parseMartingale(false)},
+	{"antimartingale", "x0", "x удваивается после выигрыша, после проигрыша - снова x0", parseMartingale(true)},
+	{"martingale-bet", "x,n", "x фиксирован, ставка удваивается после проигрыша (не больше n раз подряд)", parseMartingaleBet(false)},
+	{"antimartingale-bet", "x,n", "x фиксирован, ставка удваивается после выигрыша (не больше n раз подряд)", parseMartingaleBet(true)},
+	{"window-mean", "k", "x - среднее последних k мультипликаторов", parseWindow("mean")},
+	{"window-median", "k", "x - медиана последних k мультипликаторов", parseWindow("median")},
+	{"window-max", "k", "x - максимум последних k мультипликаторов", parseWindow("max")},
+	{"mix", "s1|s2|...", "каждый игрок на старте случайно выбирает одну из стратегий", nil},
+}
+
+// Help возвращает справку по стратегиям для флага -strategy.
+func Help() string {
+	var buf strings.Builder
+	for _, s := range strategies {
+		buf.WriteString("\n- ")
+		buf.WriteString(s.name)
+		if s.usage != "" {
+			buf.WriteByte(':')
+			buf.WriteString(s.usage)
+		}
+		buf.WriteString(" - ")
+		buf.WriteString(s.help)
+	}
+	return buf.String()
+}
+
+// Parse разбирает спецификацию стратегии spec ("имя" или "имя:p1,p2,...";
+// для mix - "mix:spec1|spec2|...") для x в [min, max].
+func Parse(spec string, min, max float64) (Factory, error) {
+	name, params, _ := strings.Cut(spec, ":")
+
+	if name == "mix" {
+		return parseMix(params, min, max)
+	}
+
+	for _, s := range strategies {
+		if s.name != name || s.parse == nil {
+			continue
+		}
+
+		var args []float64
+		if params != "" {
+			for _, p := range strings.Split(params, ",") {
+				v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+				if err != nil {
+					return nil, fmt.Errorf("strategy %s: %w", name, err)
+				}
+				args = append(args, v)
+			}
+		}
+
+		f, err := s.parse(args, min, max)
+		if err != nil {
+			return nil, fmt.Errorf("strategy %s: %w", name, err)
+		}
+		return f, nil
+	}
+
+	return nil, fmt.Errorf("unknown strategy %q", name)
+}
+
+func wantArgs(args []float64, n int) error {
+	if len(args) != n {
+		return fmt.Errorf("want %d parameter(s), got %d", n, len(args))
+	}
+	for _, v := range args {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return errors.New("parameters must be finite")
+		}
+	}
+	return nil
+}
+
+func inRange(name string, v, min, max float64) error {
+	if !(min <= v && v <= max) {
+		return fmt.Errorf("%s must be in [%g, %g], got %g", name, min, max, v)
+	}
+	return nil
+}
+
+func clamp(x, min, max float64) float64 {
+	return math.Min(math.Max(x, min), max)
+}
+
+// funcStrategy — стратегия без состояния.
+type funcStrategy func() float64
+
+func (f funcStrategy) X() float64 { return f() }
+
+func parseUniform(args []float64, min, max float64) (Factory, error) {
+	if err := wantArgs(args, 0); err != nil {
+		return nil, err
+	}
+	d := max - min
+	return func(r *rand.Rand) Strategy {
+		return funcStrategy(func() float64 { return min + r.Float64()*d })
+	}, nil
+}
+
+func parseFixed(args []float64, min, max float64) (Factory, error) {
+	if err := wantArgs(args, 1); err != nil {
+		return nil, err
+	}
+	x := args[0]
+	if err := inRange("x", x, min, max); err != nil {
+		return nil, err
+	}
+	return func(*rand.Rand) Strategy {
+		return funcStrategy(func() float64 { return x })
+	}, nil
+}
+
+func parseNormal(args []float64, min, max float64) (Factory, error) {
+	if err := wantArgs(args, 2); err != nil {
+		return nil, err
+	}
+	mu, sigma := args[0], args[1]
+	if !(sigma >= 0) {
+		return nil, fmt.Errorf("sigma must be >= 0, got %g", sigma)
+	}
+	return func(r *rand.Rand) Strategy {
+		return funcStrategy(func() float64 { return clamp(mu+sigma*r.NormFloat64(), min, max) })
+	}, nil
+}
+
+func parseExp(args []float64, min, max float64) (Factory, error) {
+	if err := wantArgs(args, 1); err != nil {
+		return nil, err
+	}
+	mean := args[0]
+	if !(mean > min) {
+		return nil, fmt.Errorf("mean must be > min (%g), got %g", min, mean)
+	}
+	scale := mean - min
+	return func(r *rand.Rand) Strategy {
+		return funcStrategy(func() float64 { return clamp(min+r.ExpFloat64()*scale, min, max) })
+	}, nil
+}
+
+func parseGamma(args []float64, min, max float64) (Factory, error) {
+	if err := wantArgs(args, 2); err != nil {
+		return nil, err
+	}
+	k, theta := args[0], args[1]
+	if !(k > 0 && theta > 0) {
+		return nil, fmt.Errorf("k and theta must be > 0, got k=%g theta=%g", k, theta)
+	}
+	return func(r *rand.Rand) Strategy {
+		g := distuv.Gamma{Alpha: k, Beta: 1 / theta, Src: r}
+		return funcStrategy(func() float64 { return clamp(min+g.Rand(), min, max) })
+	}, nil
+}
+
+func parseBeta(args []float64, min, max float64) (Factory, error) {
+	if err := wantArgs(args, 2); err != nil {
+		return nil, err
+	}
+	a, b := args[0], args[1]
+	if !(a > 0 && b > 0) {
+		return nil, fmt.Errorf("a and b must be > 0, got a=%g b=%g", a, b)
+	}
+	d := max - min
+	return func(r *rand.Rand) Strategy {
+		beta := distuv.Beta{Alpha: a, Beta: b, Src: r}
+		return funcStrategy(func() float64 { return min + beta.Rand()*d })
+	}, nil
+}
+
+func parseLevels(args []float64, min, max float64) (Factory, error) {
+	if len(args) == 0 {
+		return nil, errors.New("want at least one level")
+	}
+	for _, x := range args {
+		if err := inRange("level", x, min, max); err != nil {
+			return nil, err
+		}
+	}
+	return func(r *rand.Rand) Strategy {
+		return funcStrategy(func() float64 { return args[r.IntN(len(args))] })
+	}, nil
+}
+
+type geom struct {
+	x0, r, x, min, max float64
+}
+
+func (g *geom) X() float64 {
+	x := g.x
+	g.x *= g.r
+	if !(g.min <= g.x && g.x <= g.max) {
+		g.x = g.x0
+	}
+	return x
+}
+
+func parseGeom(args []float64, min, max float64) (Factory, error) {
+	if err := wantArgs(args, 2); err != nil {
+		return nil, err
+	}
+	x0, ratio := args[0], args[1]
+	if err := inRange("x0", x0, min, max); err != nil {
+		return nil, err
+	}
+	if !(ratio > 0) {
+		return nil, fmt.Errorf("r must be > 0, got %g", ratio)
+	}
+	return func(*rand.Rand) Strategy {
+		return &geom{x0: x0, r: ratio, x: x0, min: min, max: max}
+	}, nil
+}
+
+func parseMix(params string, min, max float64) (Factory, error) {
+	if params == "" {
+		return nil, errors.New("strategy mix: want at least one strategy")
+	}
+
+	var factories []Factory
+	for _, spec := range strings.Split(params, "|") {
+		if name, _, _ := strings.Cut(spec, ":"); name == "mix" {
+			return nil, errors.New("strategy mix: nested mix is not supported")
+		}
+		f, err := Parse(spec, min, max)
+		if err != nil {
+			return nil, fmt.Errorf("strategy mix: %w", err)
+		}
+		factories = append(factories, f)
+	}
+
+	return func(r *rand.Rand) Strategy {
+		return factories[r.IntN(len(factories))](r)
+	}, nil
+}
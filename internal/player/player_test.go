@@ -0,0 +1,120 @@
+package player_test
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/aaa2ppp/be"
+
+	"github.com/aaa2ppp/multgen/internal/player"
+)
+
+func newRand() *rand.Rand { return rand.New(rand.NewPCG(1, 2)) }
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		spec string
+		err  string
+	}{
+		{"unknown", "unknown strategy"},
+		{"uniform:1", "want 0 parameter(s)"},
+		{"fixed", "want 1 parameter(s)"},
+		{"fixed:abc", "strategy fixed"},
+		{"fixed:0.5", "x must be in [1, 100]"},
+		{"normal:5,-1", "sigma must be >= 0"},
+		{"exp:1", "mean must be > min"},
+		{"gamma:0,1", "k and theta must be > 0"},
+		{"beta:1,0", "a and b must be > 0"},
+		{"levels", "want at least one level"},
+		{"levels:2,200", "level must be in"},
+		{"geom:2,0", "r must be > 0"},
+		{"fixed:NaN", "parameters must be finite"},
+		{"mix", "want at least one strategy"},
+		{"mix:fixed:2|mix:uniform", "nested mix"},
+		{"mix:fixed:2|bad", "strategy mix: unknown strategy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			_, err := player.Parse(tt.spec, 1, 100)
+			be.Err(t, err, tt.err)
+		})
+	}
+}
+
+func TestParse_Bounds(t *testing.T) {
+	specs := []string{
+		player.DefaultStrategy,
+		"normal:50,100",
+		"exp:30",
+		"gamma:2,40",
+		"beta:0.5,0.5",
+		"levels:1,10,100",
+		"geom:1,3",
+		"mix:fixed:2|exp:5",
+	}
+	for _, spec := range specs {
+		t.Run(spec, func(t *testing.T) {
+			f, err := player.Parse(spec, 1, 100)
+			be.Err(t, err, nil)
+			s := f(newRand())
+			for range 1000 {
+				x := s.X()
+				be.True(t, 1 <= x && x <= 100)
+			}
+		})
+	}
+}
+
+func TestFixed(t *testing.T) {
+	f, err := player.Parse("fixed:2.5", 1, 100)
+	be.Err(t, err, nil)
+	s := f(newRand())
+	be.Equal(t, s.X(), 2.5)
+	be.Equal(t, s.X(), 2.5)
+}
+
+func TestGeom(t *testing.T) {
+	f, err := player.Parse("geom:2,3", 1, 20)
+	be.Err(t, err, nil)
+
+	s := f(newRand())
+	var got []float64
+	for range 5 {
+		got = append(got, s.X())
+	}
+	be.Equal(t, got, []float64{2, 6, 18, 2, 6})
+
+	// у каждого игрока свой план
+	be.Equal(t, f(newRand()).X(), 2.0)
+}
+
+func TestLevels(t *testing.T) {
+	f, err := player.Parse("levels:2, 5", 1, 100)
+	be.Err(t, err, nil)
+
+	s := f(newRand())
+	seen := map[float64]int{}
+	for range 1000 {
+		seen[s.X()]++
+	}
+	be.Equal(t, len(seen), 2)
+	be.True(t, seen[2] > 0 && seen[5] > 0)
+}
+
+func TestMix(t *testing.T) {
+	f, err := player.Parse("mix:fixed:2|fixed:3", 1, 100)
+	be.Err(t, err, nil)
+
+	// игрок выбирает стратегию один раз и держится её
+	r := newRand()
+	seen := map[float64]int{}
+	for range 100 {
+		s := f(r)
+		x := s.X()
+		for range 10 {
+			be.Equal(t, s.X(), x)
+		}
+		seen[x]++
+	}
+	be.Equal(t, len(seen), 2)
+}
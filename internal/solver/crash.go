@@ -0,0 +1,48 @@
+package solver
+
+import "math/rand/v2"
+
+// crashPrecision — знаков после точки в мультипликаторе crash-игры.
+const crashPrecision = 2
+
+// crash — мультипликатор как в crash-играх: m = RTP/(1-u), округлённый вниз
+// до 2 знаков. При m < min раунд — мгновенный проигрыш (отдаём min);
+// его вероятность 1-RTP и есть доля казино, поэтому отдельной "монетки"
+// в Solve нет (PassProbability = 1).
+//
+// Для x в [min, max): P(m > x) = RTP/g, где g — ближайшее к x сверху значение
+// с 2 знаками, т.е. RTP(x) = RTP·x/g — округление вниз чуть в пользу казино.
+type crash struct {
+	rtp      float64
+	min, max float64
+	rnd      rounder
+}
+
+func newCrash(cfg Config) (Generator, error) {
+	return &crash{
+		rtp: cfg.RTP,
+		min: cfg.Min,
+		max: cfg.Max,
+		rnd: newRounder(crashPrecision, RoundFloor),
+	}, nil
+}
+
+func (g *crash) PassProbability() float64 { return 1 }
+
+func (g *crash) Generate(r *rand.Rand) float64 {
+	u := r.Float64()
+	m := g.rnd.round(g.rtp / (1 - u))
+	return min(max(m, g.min), g.max)
+}
+
+func (g *crash) Survival(x float64) float64 {
+	switch {
+	case x < g.min:
+		return 1
+	case x >= g.max:
+		return 0
+	}
+	// floor(v) > x <=> v >= hi
+	hi := (g.rnd.floorN(x) + 1) / g.rnd.scale
+	return min(g.rtp/hi, 1)
+}
@@ -1,13 +1,13 @@
 package solver
 
-func defaultAlgorithm() Algorithm {
-	return Algorithms[len(Algorithms)-1] // min
-}
-
 func DefaultConfig() Config {
 	return Config{
 		RTP:       1,
-		Algorithm: defaultAlgorithm().Name,
+		Algorithm: DefaultAlgorithm,
 		Alpha:     1,
+		Min:       DefaultMin,
+		Max:       DefaultMax,
+		Precision: -1,
+		Rounding:  RoundFloor,
 	}
 }
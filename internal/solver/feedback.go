@@ -0,0 +1,92 @@
+package solver
+
+import (
+	"math"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+)
+
+// feedbackGain — коэффициент интегрального звена: на сколько меняется
+// коэффициент выплат g при долге перед игроками в одну среднюю ставку.
+const feedbackGain = 0.1
+
+// feedback — алгоритм с замкнутым контуром управления RTP.
+//
+// Основа — pareto1 с масштабом c на [min, max]: m = c/(1-u), P(m > x) = c/x
+// при c <= x < max. Контур подстраивает один коэффициент g = p*c, где p —
+// вероятность пропуска: при g <= 1 меняется p (c = 1), при g > 1 — масштаб
+// (p = 1). Игрок, который ставит 1 и получает x при m > x, в среднем
+// получает p*c = g при любом x в [c, max).
+//
+// Без результатов раундов (-cli, /get, /stream) g = RTP, т.е. это pareto1
+// с долей казино 1-RTP. С ними (Observe) g пересчитывается после каждого раунда:
+//
+//	g = RTP + gain * (RTP*Σbet - Σpayout) / avgBet
+//
+// т.е. растёт, пока игрокам недоплачено, и падает, пока переплачено.
+// Накопленный долг ограничен, поэтому Σpayout/Σbet сходится к RTP
+// и при других моделях выплат (например, ставка x и выплата x).
+type feedback struct {
+	rtp  float64
+	min  float64
+	max  float64
+	gain atomic.Uint64 // math.Float64bits(g), читается в Solve без блокировки
+
+	mu     sync.Mutex
+	rounds int
+	bet    float64 // сумма ставок
+	payout float64 // сумма выплат
+}
+
+func newFeedback(cfg Config) (Generator, error) {
+	f := &feedback{rtp: cfg.RTP, min: cfg.Min, max: cfg.Max}
+	f.gain.Store(math.Float64bits(cfg.RTP))
+	return f, nil
+}
+
+func (f *feedback) load() float64 { return math.Float64frombits(f.gain.Load()) }
+
+// scale — масштаб c распределения при коэффициенте g.
+func scale(g float64) float64 { return max(g, 1) }
+
+func (f *feedback) Generate(r *rand.Rand) float64 {
+	m := scale(f.load()) / (1 - r.Float64())
+	return min(max(m, f.min), f.max)
+}
+
+func (f *feedback) Survival(x float64) float64 {
+	c := scale(f.load())
+	switch {
+	case x < f.min:
+		return 1
+	case x >= f.max:
+		return 0
+	case x < c:
+		return 1
+	}
+	return c / x
+}
+
+func (f *feedback) PassProbability() float64 {
+	return min(f.load(), 1)
+}
+
+func (f *feedback) Observe(bet float64, _ bool, payout float64) {
+	if !(bet > 0) || payout < 0 {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.rounds++
+	f.bet += bet
+	f.payout += payout
+
+	avgBet := f.bet / float64(f.rounds)
+	debt := (f.rtp*f.bet - f.payout) / avgBet
+
+	g := min(max(f.rtp+feedbackGain*debt, 0), f.max)
+	f.gain.Store(math.Float64bits(g))
+}
@@ -0,0 +1,90 @@
+package solver
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
+)
+
+// Generator генерирует мультипликаторы. Экземпляр создаётся фабрикой
+// для каждого Solver; Generate может вызываться конкурентно. Вся случайность
+// должна браться из r — тогда последовательность воспроизводима по Config.Seed.
+type Generator interface {
+	Generate(r *rand.Rand) float64
+}
+
+// PassController реализуют генераторы, которые сами определяют вероятность
+// пропуска к генератору (вместо фиксированного cfg.RTP в Solve).
+type PassController interface {
+	PassProbability() float64
+}
+
+// Observer реализуют генераторы, которым нужны результаты раундов (см. Solver.Observe).
+type Observer interface {
+	Observe(bet float64, win bool, payout float64)
+}
+
+// Survivor реализуют генераторы с известным распределением: Survival(x) = P(m > x)
+// для значений Generate. Нужен для Solver.TheoreticalRTP.
+type Survivor interface {
+	Survival(x float64) float64
+}
+
+// Factory создаёт генератор для заданной конфигурации солвера.
+type Factory func(cfg Config) (Generator, error)
+
+type Algorithm struct {
+	Name        string
+	Description string
+	factory     Factory
+}
+
+var registry struct {
+	mu    sync.RWMutex
+	algos []Algorithm
+}
+
+// Register регистрирует алгоритм генерации мультипликаторов. Имена
+// сравниваются без учёта регистра. Паникует при пустом имени, nil-фабрике
+// или повторной регистрации — по аналогии с database/sql.Register.
+func Register(name, description string, factory Factory) {
+	if name == "" {
+		panic("solver: Register algorithm with empty name")
+	}
+	if factory == nil {
+		panic("solver: Register factory is nil for algorithm " + name)
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	for _, a := range registry.algos {
+		if strings.EqualFold(a.Name, name) {
+			panic(fmt.Sprintf("solver: Register called twice for algorithm %q", name))
+		}
+	}
+
+	registry.algos = append(registry.algos, Algorithm{name, description, factory})
+}
+
+// Lookup возвращает зарегистрированный алгоритм по имени (без учёта регистра).
+func Lookup(name string) (Algorithm, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	for _, a := range registry.algos {
+		if strings.EqualFold(a.Name, name) {
+			return a, true
+		}
+	}
+	return Algorithm{}, false
+}
+
+// Algorithms возвращает зарегистрированные алгоритмы в порядке регистрации.
+func Algorithms() []Algorithm {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	return append([]Algorithm(nil), registry.algos...)
+}
@@ -0,0 +1,81 @@
+package solver
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Reloadable — солвер, конфигурацию которого можно сменить на лету.
+// Методы солвера читают текущий *Solver одной атомарной загрузкой, без
+// блокировок; Reload строит новый солвер и подменяет его целиком.
+//
+// Состояние старого солвера (потоки, статистика раундов, контур feedback)
+// при смене не переносится.
+type Reloadable struct {
+	cur atomic.Pointer[Solver]
+	mu  sync.Mutex // сериализует Reload
+}
+
+func NewReloadable(s *Solver) *Reloadable {
+	r := &Reloadable{}
+	r.cur.Store(s)
+	return r
+}
+
+// Solver возвращает текущий солвер.
+func (r *Reloadable) Solver() *Solver { return r.cur.Load() }
+
+// Config возвращает конфигурацию текущего солвера.
+func (r *Reloadable) Config() Config { return r.cur.Load().Config() }
+
+// Reload создаёт солвер с конфигурацией cfg (см. New) и делает его текущим.
+// Если cfg некорректна, текущий солвер остаётся прежним.
+func (r *Reloadable) Reload(cfg Config) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, err := New(cfg)
+	if err != nil {
+		return err
+	}
+	r.cur.Store(s)
+
+	return nil
+}
+
+// Update применяет fn к копии текущей конфигурации и перезагружает солвер
+// с результатом. Зерно в копии сброшено в 0 (новое случайное), чтобы после
+// перезагрузки последовательность не повторялась с начала; fn может задать его явно.
+func (r *Reloadable) Update(fn func(cfg *Config) error) (Config, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cfg := r.cur.Load().Config()
+	cfg.Seed = 0
+	if err := fn(&cfg); err != nil {
+		return Config{}, err
+	}
+
+	s, err := New(cfg)
+	if err != nil {
+		return Config{}, err
+	}
+	r.cur.Store(s)
+
+	return s.Config(), nil
+}
+
+func (r *Reloadable) Solve() float64         { return r.cur.Load().Solve() }
+func (r *Reloadable) Precision() int         { return r.cur.Load().Precision() }
+func (r *Reloadable) Seed() uint64           { return r.cur.Load().Seed() }
+func (r *Reloadable) RoundStats() RoundStats { return r.cur.Load().RoundStats() }
+
+func (r *Reloadable) SolvePrecision() (float64, int) { return r.cur.Load().SolvePrecision() }
+
+func (r *Reloadable) Observe(bet float64, win bool, payout float64) {
+	r.cur.Load().Observe(bet, win, payout)
+}
+
+func (r *Reloadable) TheoreticalRTP(x float64) (float64, bool) {
+	return r.cur.Load().TheoreticalRTP(x)
+}
@@ -0,0 +1,97 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+)
+
+// Rounding — режим округления мультипликатора до Config.Precision знаков.
+type Rounding string
+
+const (
+	RoundFloor   Rounding = "floor"   // вниз (в пользу казино)
+	RoundNearest Rounding = "nearest" // к ближайшему
+	RoundCeil    Rounding = "ceil"    // вверх
+)
+
+// MaxPrecision — максимальное число знаков после точки.
+const MaxPrecision = 8
+
+func (m Rounding) validate() error {
+	switch m {
+	case RoundFloor, RoundNearest, RoundCeil:
+		return nil
+	}
+	return fmt.Errorf("rounding must be one of %q, %q, %q, got %q", RoundFloor, RoundNearest, RoundCeil, m)
+}
+
+// rounder округляет мультипликаторы до сетки с шагом 10^-precision.
+// Нулевое значение не округляет.
+type rounder struct {
+	scale float64 // 10^precision, 0 - без округления
+	mode  Rounding
+}
+
+func newRounder(precision int, mode Rounding) rounder {
+	if precision < 0 {
+		return rounder{}
+	}
+	return rounder{scale: math.Pow10(precision), mode: mode}
+}
+
+func (r rounder) enabled() bool { return r.scale != 0 }
+
+// floorN возвращает номер узла сетки n: n/scale <= x < (n+1)/scale.
+// Поправляет ошибку умножения x*scale (например, 1.15*100 = 114.99999999999999).
+func (r rounder) floorN(x float64) float64 {
+	n := math.Floor(x * r.scale)
+	if (n+1)/r.scale <= x {
+		n++
+	} else if n/r.scale > x {
+		n--
+	}
+	return n
+}
+
+func (r rounder) round(m float64) float64 {
+	if !r.enabled() {
+		return m
+	}
+
+	n := r.floorN(m)
+	switch r.mode {
+	case RoundCeil:
+		if n/r.scale < m {
+			n++
+		}
+	case RoundNearest:
+		if m-n/r.scale >= (n+1)/r.scale-m {
+			n++
+		}
+	}
+	return n / r.scale
+}
+
+// overpay возвращает наибольшее отношение округлённого мультипликатора к
+// исходному для m >= min: floor не переплачивает, ceil добавляет до шага
+// сетки, nearest — до половины шага. Солвер делит на него вероятность
+// пропуска, чтобы округление в пользу игрока не поднимало RTP выше целевого
+// (для распределений, у которых x*P(m > x) не растёт с x, как у pareto1).
+func (r rounder) overpay(min float64) float64 {
+	if !r.enabled() {
+		return 1
+	}
+	step := 1 / r.scale
+	switch r.mode {
+	case RoundCeil:
+		return (min + step) / min
+	case RoundNearest:
+		return (min + step/2) / min
+	}
+	return 1
+}
+
+// onGrid сообщает, лежит ли x в узле сетки.
+func (r rounder) onGrid(x float64) bool {
+	return !r.enabled() || r.floorN(x)/r.scale == x
+}
@@ -0,0 +1,59 @@
+package solver
+
+import "math"
+
+// TheoreticalRTP возвращает теоретический RTP игрока, который всегда выбирает x,
+// ставит 1 и получает x при m > x (как `check -1`). Учитывает долю казино,
+// min, -d и округление до Precision. ok == false, если генератор не сообщает
+// своё распределение (не реализует Survivor).
+func (s *Solver) TheoreticalRTP(x float64) (rtp float64, ok bool) {
+	sv, ok := s.gen.(Survivor)
+	if !ok {
+		return math.NaN(), false
+	}
+
+	t, inclusive := s.winThreshold(x)
+
+	var win float64
+	if inclusive {
+		win = sv.Survival(math.Nextafter(t, math.Inf(-1)))
+	} else {
+		win = sv.Survival(t)
+	}
+
+	pass := s.passProbability()
+	win *= pass
+
+	// доля казино отдаётся как min без -d и округления
+	if s.cfg.Min > x {
+		win += 1 - pass
+	}
+
+	return x * win, true
+}
+
+// winThreshold переводит условие выигрыша "итоговый мультипликатор > x"
+// в условие на значение генератора: m > t или, если inclusive, m >= t.
+func (s *Solver) winThreshold(x float64) (t float64, inclusive bool) {
+	r := s.rnd
+	if !r.enabled() {
+		// m + delta > x <=> m >= x
+		return x, s.cfg.AddDelta
+	}
+
+	// ближайшие узлы сетки: lo <= x < hi
+	n := r.floorN(x)
+	lo, hi := n/r.scale, (n+1)/r.scale
+
+	switch r.mode {
+	case RoundCeil:
+		// ceil(m) > x <=> ceil(m) >= hi <=> m > lo
+		return lo, s.cfg.AddDelta
+	case RoundNearest:
+		// round(m) >= hi <=> m >= hi - step/2
+		return (lo + hi) / 2, true
+	default:
+		// floor(m) >= hi <=> m >= hi
+		return hi, true
+	}
+}
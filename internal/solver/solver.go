@@ -7,18 +7,27 @@ import (
 	"log"
 	"math"
 	"math/rand/v2"
-	"strings"
+	"sync"
+	"sync/atomic"
 )
 
+// Диапазон мультипликатора по умолчанию
 const (
-	MaxValue = 10000.0
+	DefaultMin = 1.0
+	DefaultMax = 10000.0
 )
 
 type Config struct {
-	RTP       float64 // целевой RTP
-	Algorithm string  // алгоритма генерации RTP
-	Alpha     float64 // параметр алгоритма paretoAlpha
-	AddDelta  bool    // добавить дельту к заначению мультипликатора (имеет смысл для алгоритма min)
+	RTP       float64  `json:"rtp"`       // целевой RTP
+	Algorithm string   `json:"algorithm"` // алгоритма генерации RTP
+	Alpha     float64  `json:"alpha"`     // параметр алгоритма paretoAlpha
+	AddDelta  bool     `json:"add_delta"` // добавить дельту к заначению мультипликатора (имеет смысл для алгоритма min)
+	Seed      uint64   `json:"seed"`      // зерно генератора случайных чисел (0 - случайное)
+	Min       float64  `json:"min"`       // минимальный мультипликатор (его же получает игрок, когда казино забирает свою долю)
+	Max       float64  `json:"max"`       // максимальный мультипликатор
+	Precision int      `json:"precision"` // знаков после точки (-1 - без округления)
+	Rounding  Rounding `json:"rounding"`  // режим округления до Precision знаков
+	DistFile  string   `json:"dist_file"` // файл с таблицей распределения для алгоритма table (CSV или JSON)
 }
 
 func (c Config) Validate() error {
@@ -32,62 +41,155 @@ func (c Config) Validate() error {
 		errs = append(errs, fmt.Errorf("alpha must be >= 1, got %g", c.Alpha))
 	}
 
+	if !(c.Min >= 1) {
+		errs = append(errs, fmt.Errorf("min multiplier must be >= 1, got %g", c.Min))
+	}
+
+	if !(c.Min <= c.Max) || math.IsInf(c.Max, 0) {
+		errs = append(errs, fmt.Errorf("max multiplier must be finite and >= min (%g), got %g", c.Min, c.Max))
+	}
+
+	if !(-1 <= c.Precision && c.Precision <= MaxPrecision) {
+		errs = append(errs, fmt.Errorf("precision must be in [-1, %d], got %d", MaxPrecision, c.Precision))
+	} else if c.Precision >= 0 {
+		if err := c.Rounding.validate(); err != nil {
+			errs = append(errs, err)
+		}
+
+		r := newRounder(c.Precision, c.Rounding)
+		if !r.onGrid(c.Min) || !r.onGrid(c.Max) {
+			errs = append(errs, fmt.Errorf("min and max multipliers must have at most %d decimal places", c.Precision))
+		}
+
+		// дельта меньше шага сетки: floor её съедает, а ceil выводит за max
+		if c.AddDelta {
+			errs = append(errs, errors.New("add delta is incompatible with precision >= 0"))
+		}
+	}
+
 	return errors.Join(errs...)
 }
 
-type algoFunc func(*Config) float64
+type (
+	algoFunc     func(*Config, *rand.Rand) float64
+	survivalFunc func(cfg *Config, x float64) float64
+)
+
+// funcGenerator — генератор без состояния.
+type funcGenerator struct {
+	cfg      Config
+	fn       algoFunc
+	survival survivalFunc
+}
+
+func (g *funcGenerator) Generate(r *rand.Rand) float64 { return g.fn(&g.cfg, r) }
+func (g *funcGenerator) Survival(x float64) float64    { return g.survival(&g.cfg, x) }
 
-type Algorithm struct {
-	Name        string
-	Description string
-	fn          algoFunc
+func stateless(fn algoFunc, survival survivalFunc) Factory {
+	return func(cfg Config) (Generator, error) { return &funcGenerator{cfg, fn, survival}, nil }
 }
 
-func pareto1() float64 {
-	u := rand.Float64()
+func pareto1(r *rand.Rand, lo, hi float64) float64 {
+	u := r.Float64()
 	m := 1 / (1 - u)
-	if m > MaxValue {
-		m = MaxValue
-	}
-	return m
+	return min(max(m, lo), hi)
 }
 
-func paretoAlpha(alpha float64) float64 {
-	u := rand.Float64()
+func paretoAlpha(r *rand.Rand, alpha, lo, hi float64) float64 {
+	u := r.Float64()
 	m := math.Pow(1-u, -1/alpha)
-	if m > MaxValue {
-		m = MaxValue
+	return min(max(m, lo), hi)
+}
+
+// paretoSurvival — P(m > x) для paretoAlpha.
+func paretoSurvival(alpha, lo, hi, x float64) float64 {
+	switch {
+	case x < lo:
+		return 1
+	case x >= hi:
+		return 0
+	case x < 1:
+		return 1
+	}
+	return math.Pow(x, -alpha)
+}
+
+// constSurvival — P(m > x) для константы v.
+func constSurvival(v, x float64) float64 {
+	if x < v {
+		return 1
 	}
-	return m
+	return 0
 }
 
-// Algorithms выбора мультипликатора
-var Algorithms = []Algorithm{
-	{
-		"pareto1",
+// DefaultAlgorithm — алгоритм, используемый вместо неизвестного.
+const DefaultAlgorithm = "min"
+
+// Встроенные алгоритмы выбора мультипликатора
+func init() {
+	Register("pareto1",
 		`"честный" (при любых x, матожидание RTP=1), но плохо сходится при больших x`,
-		func(_ *Config) float64 { return pareto1() },
-	},
-	{
-		"paretoA",
+		stateless(
+			func(cfg *Config, r *rand.Rand) float64 { return pareto1(r, cfg.Min, cfg.Max) },
+			func(cfg *Config, x float64) float64 { return paretoSurvival(1, cfg.Min, cfg.Max, x) },
+		))
+
+	Register("paretoA",
 		`"загоняем" игрока в x=1 (RTP падает с ростом x, при alpha > 1)`,
-		func(cfg *Config) float64 { return paretoAlpha(cfg.Alpha) },
-	},
-	{
-		"max",
-		fmt.Sprintf("всегда возвращает %g", MaxValue),
-		func(_ *Config) float64 { return MaxValue },
-	},
-	{
-		"min",
-		"всегда возвращает 1",
-		func(_ *Config) float64 { return 1 },
-	},
+		stateless(
+			func(cfg *Config, r *rand.Rand) float64 { return paretoAlpha(r, cfg.Alpha, cfg.Min, cfg.Max) },
+			func(cfg *Config, x float64) float64 { return paretoSurvival(cfg.Alpha, cfg.Min, cfg.Max, x) },
+		))
+
+	Register("paretoT",
+		"Pareto(alpha > 1) с точной поправкой на усечение в max:\n"+
+			"при выплате x*m (check -m) RTP(x) = rtp * x^(1-alpha) для любого x в [min, max)",
+		newParetoTruncated)
+
+	Register("crash",
+		"как в crash-играх: m = rtp/(1-u) вниз до 2 знаков, m < min - мгновенный проигрыш\n"+
+			"(вероятность 1-rtp); заменяет отдельную долю казино в Solve",
+		newCrash)
+
+	Register("table",
+		"эмпирическое распределение из -dist-file: CSV \"multiplier,weight\" (или \"multiplier,cdf\")\n"+
+			"или JSON [{\"multiplier\":m,\"weight\":w}]; доля казино пересчитывается под rtp таблицы",
+		newTable)
+
+	Register("max",
+		fmt.Sprintf("всегда возвращает max (по умолчанию %g)", DefaultMax),
+		stateless(
+			func(cfg *Config, _ *rand.Rand) float64 { return cfg.Max },
+			func(cfg *Config, x float64) float64 { return constSurvival(cfg.Max, x) },
+		))
+
+	Register("feedback",
+		"pareto1 с замкнутым контуром: по результатам раундов (Solver.Observe) подстраивает\n"+
+			"долю казино и масштаб так, чтобы фактический RTP сходился к целевому при любой стратегии x;\n"+
+			"без результатов раундов (-cli, /get, /stream) равен pareto1",
+		newFeedback)
+
+	Register(DefaultAlgorithm,
+		fmt.Sprintf("всегда возвращает min (по умолчанию %g)", DefaultMin),
+		stateless(
+			func(cfg *Config, _ *rand.Rand) float64 { return cfg.Min },
+			func(cfg *Config, x float64) float64 { return constSurvival(cfg.Min, x) },
+		))
 }
 
 type Solver struct {
-	cfg    Config
-	algoFn algoFunc
+	cfg     Config
+	rnd     rounder
+	overpay float64 // переплата от округления, см. rounder.overpay
+
+	streams atomic.Uint64 // количество выданных потоков (индекс следующего)
+	pool    sync.Pool     // *Stream для Solve
+
+	gen Generator
+	pc  PassController // nil, если генератор не управляет вероятностью пропуска
+	obs Observer       // nil, если генератору не нужны результаты раундов
+
+	stats roundStats // итоги раундов из Observe
 }
 
 func New(cfg Config) (*Solver, error) {
@@ -95,40 +197,92 @@ func New(cfg Config) (*Solver, error) {
 		return nil, err
 	}
 
-	var algoFn algoFunc
-	for _, algo := range Algorithms {
-		if strings.EqualFold(algo.Name, cfg.Algorithm) {
-			algoFn = algo.fn
-			break
-		}
+	algo, ok := Lookup(cfg.Algorithm)
+	if !ok {
+		algo, _ = Lookup(DefaultAlgorithm)
+		log.Printf("instead of the unknown %q algorithm, %q algorithm will be used", cfg.Algorithm, algo.Name)
+	}
+	cfg.Algorithm = algo.Name
+
+	if cfg.Seed == 0 {
+		cfg.Seed = rand.Uint64()
 	}
 
-	if algoFn == nil {
-		a := defaultAlgorithm()
-		log.Printf("instead of the unknown %q algorithm, %q algorithm will be used", cfg.Algorithm, a.Name)
-		cfg.Algorithm = a.Name
-		algoFn = a.fn
+	gen, err := algo.factory(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", algo.Name, err)
 	}
 
-	return &Solver{
-		cfg:    cfg,
-		algoFn: algoFn,
-	}, nil
+	rnd := newRounder(cfg.Precision, cfg.Rounding)
+	s := &Solver{cfg: cfg, rnd: rnd, overpay: rnd.overpay(cfg.Min), gen: gen}
+	s.pc, _ = gen.(PassController)
+	s.obs, _ = gen.(Observer)
+	s.pool.New = func() any { return s.NewStream() }
+
+	return s, nil
 }
 
+// Seed возвращает зерно генератора случайных чисел. Если в конфигурации
+// зерно не задано, это случайно выбранное значение — его можно передать
+// в -seed, чтобы воспроизвести последовательность.
+func (s *Solver) Seed() uint64 { return s.cfg.Seed }
+
+// Solve возвращает очередной мультипликатор. Безопасен для конкурентного
+// вызова: каждая горутина берёт из пула собственный поток (см. Stream),
+// поэтому общего состояния и блокировок на горячем пути нет.
 func (s *Solver) Solve() float64 {
+	st := s.pool.Get().(*Stream)
+	multiplier := st.Solve()
+	s.pool.Put(st)
+	return multiplier
+}
+
+// SolvePrecision возвращает мультипликатор и число знаков, с которым его
+// выводить. У Reloadable конфигурация может смениться между отдельными
+// вызовами Solve и Precision, здесь оба значения — от одного солвера.
+func (s *Solver) SolvePrecision() (multiplier float64, precision int) {
+	return s.Solve(), s.cfg.Precision
+}
+
+func (s *Solver) solve(r *rand.Rand) float64 {
 
 	// забираем свою долю
-	p := rand.Float64()
-	if p > s.cfg.RTP {
-		return 1
+	p := r.Float64()
+	if p > s.passProbability() {
+		return s.cfg.Min
 	}
 
-	multiplier := s.algoFn(&s.cfg)
+	multiplier := s.gen.Generate(r)
 
 	if s.cfg.AddDelta {
 		multiplier = math.Nextafter(multiplier, multiplier+1)
 	}
 
-	return multiplier
+	return s.rnd.round(multiplier)
+}
+
+// Config возвращает конфигурацию солвера (с фактическими алгоритмом и зерном).
+func (s *Solver) Config() Config { return s.cfg }
+
+// Precision возвращает число знаков после точки в мультипликаторах (-1 - без округления).
+func (s *Solver) Precision() int { return s.cfg.Precision }
+
+// Observe сообщает солверу результат раунда: ставку, выигрыш и выплату.
+// Раунд учитывается в RoundStats и передаётся алгоритмам с обратной связью (feedback).
+func (s *Solver) Observe(bet float64, win bool, payout float64) {
+	s.stats.add(bet, win, payout)
+	if s.obs != nil {
+		s.obs.Observe(bet, win, payout)
+	}
+}
+
+// RoundStats возвращает итоги раундов, о которых сообщили через Observe,
+// в том числе фактический RTP.
+func (s *Solver) RoundStats() RoundStats { return s.stats.get() }
+
+func (s *Solver) passProbability() float64 {
+	if s.pc != nil {
+		return s.pc.PassProbability() / s.overpay
+	}
+	return s.cfg.RTP / s.overpay
 }
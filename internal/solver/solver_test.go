@@ -0,0 +1,460 @@
+package solver_test
+
+import (
+	"math"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aaa2ppp/be"
+
+	"github.com/aaa2ppp/multgen/internal/solver"
+)
+
+func TestFeedback(t *testing.T) {
+	const (
+		rtp    = 0.9
+		rounds = 200_000
+	)
+
+	// Модели игры: сколько игрок ставит и сколько получает при выигрыше.
+	games := []struct {
+		name   string
+		bet    func(x float64) float64
+		payout func(x float64) float64
+	}{
+		{"payment x, profit x", func(x float64) float64 { return x }, func(x float64) float64 { return x }},
+		{"payment 1, profit x", func(float64) float64 { return 1 }, func(x float64) float64 { return x }},
+	}
+
+	for _, g := range games {
+		t.Run(g.name, func(t *testing.T) {
+			cfg := solver.DefaultConfig()
+			cfg.Algorithm = "feedback"
+			cfg.RTP = rtp
+
+			s, err := solver.New(cfg)
+			be.Err(be.Require(t), err, nil)
+
+			var totalBet, totalPayout float64
+			for range rounds {
+				x := 1 + rand.Float64()*99
+				m := s.Solve()
+
+				bet := g.bet(x)
+				win := m > x
+				var payout float64
+				if win {
+					payout = g.payout(x)
+				}
+				s.Observe(bet, win, payout)
+
+				totalBet += bet
+				totalPayout += payout
+			}
+
+			got := totalPayout / totalBet
+			if math.Abs(got-rtp) > 0.01 {
+				t.Errorf("rtp = %g, want %g", got, rtp)
+			}
+		})
+	}
+}
+
+// Без Observe (-cli, /get, /stream) feedback — pareto1 с долей казино:
+// RTP(x) = rtp при любом x, а не rtp*x, как у max.
+func TestFeedback_OpenLoop(t *testing.T) {
+	const (
+		rtp = 0.9
+		n   = 1_000_000
+	)
+
+	cfg := solver.DefaultConfig()
+	cfg.Algorithm = "feedback"
+	cfg.RTP = rtp
+	cfg.Seed = 1
+
+	s, err := solver.New(cfg)
+	be.Err(be.Require(t), err, nil)
+
+	xs := []float64{1.5, 2, 10}
+	wins := make([]int, len(xs))
+
+	st := s.NewStream()
+	for range n {
+		m := st.Solve()
+		for i, x := range xs {
+			if m > x {
+				wins[i]++
+			}
+		}
+	}
+
+	for i, x := range xs {
+		want, ok := s.TheoreticalRTP(x)
+		be.True(be.Require(t), ok)
+		if math.Abs(want-rtp) > 1e-9 {
+			t.Errorf("x=%g: theoretical rtp = %g, want %g", x, want, rtp)
+		}
+		got := x * float64(wins[i]) / n
+		if math.Abs(got-rtp) > 0.015 {
+			t.Errorf("x=%g: rtp = %g, want %g", x, got, rtp)
+		}
+	}
+}
+
+type constGenerator float64
+
+func (g constGenerator) Generate(*rand.Rand) float64 { return float64(g) }
+
+func TestRegister(t *testing.T) {
+	solver.Register("test-const", "всегда возвращает 2", func(cfg solver.Config) (solver.Generator, error) {
+		return constGenerator(2), nil
+	})
+
+	a, ok := solver.Lookup("TEST-CONST")
+	be.True(be.Require(t), ok)
+	be.Equal(t, a.Name, "test-const")
+
+	cfg := solver.DefaultConfig()
+	cfg.Algorithm = "test-const"
+	s, err := solver.New(cfg)
+	be.Err(be.Require(t), err, nil)
+	be.Equal(t, s.Solve(), 2.0)
+
+	defer func() { be.True(t, recover() != nil) }()
+	solver.Register("Test-Const", "", func(solver.Config) (solver.Generator, error) { return constGenerator(3), nil })
+}
+
+func TestSeed(t *testing.T) {
+	cfg := solver.DefaultConfig()
+	cfg.Algorithm = "pareto1"
+	cfg.RTP = 0.9
+	cfg.Seed = 42
+
+	s1, err := solver.New(cfg)
+	be.Err(be.Require(t), err, nil)
+	s2, err := solver.New(cfg)
+	be.Err(be.Require(t), err, nil)
+
+	st1, st2 := s1.NewStream(), s2.NewStream()
+	for range 1000 {
+		be.Equal(be.Require(t), st1.Solve(), st2.Solve())
+	}
+
+	// следующий поток того же солвера — другая последовательность
+	st3 := s1.NewStream()
+	var same int
+	for range 1000 {
+		if st1.Solve() == st3.Solve() {
+			same++
+		}
+	}
+	be.True(t, same < 1000)
+
+	cfg.Seed = 0
+	s3, err := solver.New(cfg)
+	be.Err(be.Require(t), err, nil)
+	be.True(t, s3.Seed() != 0)
+}
+
+func BenchmarkSolve(b *testing.B) {
+	cfg := solver.DefaultConfig()
+	cfg.Algorithm = "pareto1"
+	cfg.RTP = 0.9
+
+	s, err := solver.New(cfg)
+	be.Err(be.Require(b), err, nil)
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			s.Solve()
+		}
+	})
+}
+
+func TestParetoTruncated(t *testing.T) {
+	const (
+		rtp   = 0.9
+		alpha = 1.2 // при alpha близком к 1 поправка на усечение велика (~0.12)
+		n     = 1_000_000
+	)
+
+	cfg := solver.DefaultConfig()
+	cfg.Algorithm = "paretoT"
+	cfg.RTP = rtp
+	cfg.Alpha = alpha
+	cfg.Seed = 1
+
+	s, err := solver.New(cfg)
+	be.Err(be.Require(t), err, nil)
+
+	xs := []float64{1, 2, 10, 100}
+	sums := make([]float64, len(xs))
+
+	st := s.NewStream()
+	for range n {
+		m := st.Solve()
+		for i, x := range xs {
+			if m > x {
+				sums[i] += m
+			}
+		}
+	}
+
+	for i, x := range xs {
+		got := sums[i] / n
+		want := rtp * math.Pow(x, 1-alpha)
+		if math.Abs(got-want) > 0.05 {
+			t.Errorf("x=%g: rtp = %g, want %g", x, got, want)
+		}
+	}
+
+	cfg.Alpha = 1
+	_, err = solver.New(cfg)
+	be.Err(t, err)
+}
+
+func TestMinMax(t *testing.T) {
+	cfg := solver.DefaultConfig()
+	cfg.RTP = 0.5
+	cfg.Min = 2
+	cfg.Max = 100
+
+	for _, algo := range []string{"pareto1", "paretoA", "max", "min"} {
+		t.Run(algo, func(t *testing.T) {
+			cfg := cfg
+			cfg.Algorithm = algo
+			s, err := solver.New(cfg)
+			be.Err(be.Require(t), err, nil)
+
+			for range 10_000 {
+				m := s.Solve()
+				if m < cfg.Min || m > cfg.Max {
+					t.Fatalf("multiplier %g out of [%g, %g]", m, cfg.Min, cfg.Max)
+				}
+			}
+		})
+	}
+
+	cfg.Min, cfg.Max = 100, 2
+	_, err := solver.New(cfg)
+	be.Err(t, err)
+}
+
+func TestRounding(t *testing.T) {
+	const (
+		x = 1.5
+		n = 2_000_000
+	)
+
+	for _, mode := range []solver.Rounding{solver.RoundFloor, solver.RoundNearest, solver.RoundCeil} {
+		t.Run(string(mode), func(t *testing.T) {
+			cfg := solver.DefaultConfig()
+			cfg.Algorithm = "pareto1"
+			cfg.RTP = 0.9
+			cfg.Precision = 2
+			cfg.Rounding = mode
+			cfg.Seed = 1
+
+			s, err := solver.New(cfg)
+			be.Err(be.Require(t), err, nil)
+
+			want, ok := s.TheoreticalRTP(x)
+			be.True(be.Require(t), ok)
+
+			st := s.NewStream()
+			var wins int
+			for range n {
+				m := st.Solve()
+				if c := m * 100; math.Abs(c-math.Round(c)) > 1e-6 {
+					t.Fatalf("multiplier %v has more than 2 decimal places", m)
+				}
+				if m > x {
+					wins++
+				}
+			}
+
+			got := x * float64(wins) / n
+			if math.Abs(got-want) > 0.002 {
+				t.Errorf("rtp = %g, want %g", got, want)
+			}
+		})
+	}
+
+	// floor в пользу казино: RTP = 0.9 * 1.5/1.51
+	cfg := solver.DefaultConfig()
+	cfg.Algorithm = "pareto1"
+	cfg.RTP = 0.9
+	cfg.Precision = 2
+	s, err := solver.New(cfg)
+	be.Err(be.Require(t), err, nil)
+	rtp, _ := s.TheoreticalRTP(x)
+	be.True(t, math.Abs(rtp-0.9*1.5/1.51) < 1e-12)
+
+	// nearest и ceil округляют в пользу игрока, но RTP не выше целевого
+	for _, mode := range []solver.Rounding{solver.RoundNearest, solver.RoundCeil} {
+		cfg.Rounding = mode
+		s, err := solver.New(cfg)
+		be.Err(be.Require(t), err, nil)
+		for _, x := range []float64{1, 1.004, 1.005, 1.009, 1.5, 1.999, 9.999, 100} {
+			if rtp, _ := s.TheoreticalRTP(x); rtp > cfg.RTP+1e-12 {
+				t.Errorf("%s: x=%g: rtp = %g > %g", mode, x, rtp, cfg.RTP)
+			}
+		}
+	}
+	cfg.Rounding = solver.RoundFloor
+
+	cfg.Min = 1.005
+	_, err = solver.New(cfg)
+	be.Err(t, err)
+
+	// дельта с округлением: floor съел бы её (min -> 1.00), ceil вывел бы за max
+	for _, tt := range []struct {
+		algo string
+		mode solver.Rounding
+	}{
+		{"min", solver.RoundFloor},
+		{"max", solver.RoundCeil},
+	} {
+		cfg := solver.DefaultConfig()
+		cfg.Algorithm = tt.algo
+		cfg.RTP = 0.9
+		cfg.Precision = 2
+		cfg.Rounding = tt.mode
+		cfg.AddDelta = true
+		_, err := solver.New(cfg)
+		be.Err(t, err, "add delta is incompatible with precision")
+	}
+}
+
+func TestCrash(t *testing.T) {
+	const (
+		rtp = 0.97
+		n   = 1_000_000
+	)
+
+	cfg := solver.DefaultConfig()
+	cfg.Algorithm = "crash"
+	cfg.RTP = rtp
+	cfg.Seed = 1
+
+	s, err := solver.New(cfg)
+	be.Err(be.Require(t), err, nil)
+
+	xs := []float64{1, 1.5, 2, 10}
+	wins := make([]int, len(xs))
+	var busts int
+
+	st := s.NewStream()
+	for range n {
+		m := st.Solve()
+		if m == 1 {
+			busts++
+		}
+		for i, x := range xs {
+			if m > x {
+				wins[i]++
+			}
+		}
+	}
+
+	// мгновенный проигрыш: rtp/(1-u) < 1.01 (1.00 после округления)
+	if got, want := float64(busts)/n, 1-rtp/1.01; math.Abs(got-want) > 0.002 {
+		t.Errorf("bust probability = %g, want %g", got, want)
+	}
+
+	for i, x := range xs {
+		want, ok := s.TheoreticalRTP(x)
+		be.True(be.Require(t), ok)
+		got := x * float64(wins[i]) / n
+		if math.Abs(got-want) > 0.01 {
+			t.Errorf("x=%g: rtp = %g, want %g", x, got, want)
+		}
+	}
+}
+
+func TestTable(t *testing.T) {
+	dir := t.TempDir()
+
+	// "честная" таблица: v*P(m >= v) = 1 для всех значений
+	files := map[string]string{
+		"weights.csv": "multiplier,weight\n1,4\n2,2\n4,1\n8,1\n",
+		"cdf.csv":     "multiplier;cdf\n1;0,5\n2;0,75\n4;0,875\n8;1\n",
+		"table.json":  `[{"multiplier":1,"weight":0.5},{"multiplier":2,"weight":0.25},{"multiplier":4,"weight":0.125},{"multiplier":8,"weight":0.125}]`,
+	}
+
+	for name, data := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			be.Err(be.Require(t), os.WriteFile(path, []byte(data), 0o644), nil)
+
+			cfg := solver.DefaultConfig()
+			cfg.Algorithm = "table"
+			cfg.RTP = 0.9
+			cfg.DistFile = path
+			cfg.Seed = 1
+
+			s, err := solver.New(cfg)
+			be.Err(be.Require(t), err, nil)
+
+			const n = 400_000
+			counts := map[float64]int{}
+			st := s.NewStream()
+			for range n {
+				counts[st.Solve()]++
+			}
+
+			// skim отдаёт min = 1, значит P(1) = 0.1 + 0.9*0.5
+			want := map[float64]float64{1: 0.55, 2: 0.225, 4: 0.1125, 8: 0.1125}
+			for v, p := range want {
+				if got := float64(counts[v]) / n; math.Abs(got-p) > 0.005 {
+					t.Errorf("P(%g) = %g, want %g", v, got, p)
+				}
+			}
+
+			rtp, ok := s.TheoreticalRTP(math.Nextafter(4, 0))
+			be.True(be.Require(t), ok)
+			be.True(t, math.Abs(rtp-0.9) < 1e-9)
+		})
+	}
+
+	t.Run("rtp of table is too low", func(t *testing.T) {
+		path := filepath.Join(dir, "low.csv")
+		be.Err(be.Require(t), os.WriteFile(path, []byte("1,1\n1.5,1\n"), 0o644), nil)
+
+		cfg := solver.DefaultConfig()
+		cfg.Algorithm = "table"
+		cfg.DistFile = path
+		_, err := solver.New(cfg)
+		be.Err(t, err)
+	})
+}
+
+func TestReloadable(t *testing.T) {
+	cfg := solver.DefaultConfig()
+	cfg.Seed = 42
+	s, err := solver.New(cfg)
+	be.Err(be.Require(t), err, nil)
+
+	rs := solver.NewReloadable(s)
+	be.Equal(t, rs.Solve(), 1.0)
+
+	got, err := rs.Update(func(cfg *solver.Config) error {
+		cfg.Algorithm = "max"
+		return nil
+	})
+	be.Err(be.Require(t), err, nil)
+	be.Equal(t, got.Algorithm, "max")
+	be.True(t, got.Seed != 42) // без явного seed последовательность не повторяется
+	be.Equal(t, rs.Solve(), solver.DefaultMax)
+
+	_, err = rs.Update(func(cfg *solver.Config) error {
+		cfg.RTP = 2
+		return nil
+	})
+	be.Err(t, err)
+	be.Equal(t, rs.Config().Algorithm, "max")
+}
@@ -0,0 +1,42 @@
+package solver
+
+import "sync"
+
+// RoundStats — итоги раундов, о которых сообщили через Solver.Observe.
+type RoundStats struct {
+	Rounds uint64  // число раундов
+	Wins   uint64  // из них выигрышных
+	Bet    float64 // сумма ставок
+	Payout float64 // сумма выплат
+}
+
+// RTP возвращает фактический RTP: сумма выплат / сумма ставок (0, если ставок не было).
+func (st RoundStats) RTP() float64 {
+	if st.Bet == 0 {
+		return 0
+	}
+	return st.Payout / st.Bet
+}
+
+type roundStats struct {
+	mu sync.Mutex
+	RoundStats
+}
+
+func (st *roundStats) add(bet float64, win bool, payout float64) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.Rounds++
+	if win {
+		st.Wins++
+	}
+	st.Bet += bet
+	st.Payout += payout
+}
+
+func (st *roundStats) get() RoundStats {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.RoundStats
+}
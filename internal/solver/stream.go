@@ -0,0 +1,35 @@
+package solver
+
+import (
+	"encoding/binary"
+	"math/rand/v2"
+)
+
+// Stream — независимый поток мультипликаторов солвера со своим генератором
+// случайных чисел. Потоки получаются из одного Config.Seed и не пересекаются:
+// ключ ChaCha8 потока i — это пара (seed, i).
+//
+// Stream не потокобезопасен и предназначен для одной горутины. Поток 0 —
+// первый выданный NewStream — воспроизводим по seed (так работает -cli).
+// Потоки Solve берутся из sync.Pool и пересоздаются после GC, поэтому
+// конкурентная последовательность в целом не воспроизводима.
+type Stream struct {
+	s   *Solver
+	rng *rand.Rand
+}
+
+// NewStream возвращает очередной независимый поток солвера.
+func (s *Solver) NewStream() *Stream {
+	i := s.streams.Add(1) - 1
+	return &Stream{s: s, rng: newRand(s.cfg.Seed, i)}
+}
+
+func (st *Stream) Solve() float64 { return st.s.solve(st.rng) }
+
+// newRand возвращает генератор ChaCha8, полностью определяемый seed и номером потока.
+func newRand(seed, stream uint64) *rand.Rand {
+	var key [32]byte
+	binary.LittleEndian.PutUint64(key[0:], seed)
+	binary.LittleEndian.PutUint64(key[8:], stream)
+	return rand.New(rand.NewChaCha8(key))
+}
@@ -0,0 +1,280 @@
+package solver
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// table — эмпирическое распределение из файла Config.DistFile: пары
+// (мультипликатор, вес) или CDF. Выборка за O(1) методом псевдонимов (Vose).
+//
+// При загрузке считается RTP самой таблицы — лучший RTP игрока, который
+// всегда выбирает один x >= min (ставка 1, выплата x при m > x):
+//
+//	R = max(min·P(m > min), max_{v_i > min} v_i·P(m >= v_i))
+//
+// и вероятность пропуска в Solve масштабируется до RTP/R. Тогда ни один
+// фиксированный x не получает больше целевого RTP, а для "честной" таблицы
+// (v·P(m >= v) одинаково для всех значений) целевой RTP получает игрок,
+// выбирающий x сколь угодно близко снизу к любому её значению.
+type table struct {
+	pass float64
+
+	// метод псевдонимов
+	prob  []float64
+	alias []int
+	value []float64 // по возрастанию
+
+	tail []float64 // tail[i] = P(m > value[i]), для Survival
+}
+
+type tableRow struct {
+	Multiplier float64 `json:"multiplier"`
+	Weight     float64 `json:"weight"`
+}
+
+func newTable(cfg Config) (Generator, error) {
+	if cfg.DistFile == "" {
+		return nil, errors.New("distribution file is required (-dist-file)")
+	}
+
+	rows, err := readTable(cfg.DistFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildTable(cfg, rows)
+}
+
+func buildTable(cfg Config, rows []tableRow) (*table, error) {
+	if len(rows) == 0 {
+		return nil, errors.New("distribution table is empty")
+	}
+
+	// объединяем повторы и нормируем веса
+	slices.SortFunc(rows, func(a, b tableRow) int { return cmp.Compare(a.Multiplier, b.Multiplier) })
+
+	var (
+		values  []float64
+		weights []float64
+		total   float64
+	)
+	for _, r := range rows {
+		if !(cfg.Min <= r.Multiplier && r.Multiplier <= cfg.Max) {
+			return nil, fmt.Errorf("multiplier %g out of [%g, %g]", r.Multiplier, cfg.Min, cfg.Max)
+		}
+		if !(r.Weight >= 0) {
+			return nil, fmt.Errorf("weight of multiplier %g must be >= 0, got %g", r.Multiplier, r.Weight)
+		}
+		if n := len(values); n > 0 && values[n-1] == r.Multiplier {
+			weights[n-1] += r.Weight
+		} else {
+			values = append(values, r.Multiplier)
+			weights = append(weights, r.Weight)
+		}
+		total += r.Weight
+	}
+
+	if !(total > 0) {
+		return nil, errors.New("total weight of distribution table must be > 0")
+	}
+
+	n := len(values)
+	p := make([]float64, n)
+	for i := range weights {
+		p[i] = weights[i] / total
+	}
+
+	// хвосты: tail[i] = P(m > values[i])
+	tail := make([]float64, n)
+	var sum float64
+	for i := n - 1; i >= 0; i-- {
+		tail[i] = sum
+		sum += p[i]
+	}
+
+	t := &table{value: values, tail: tail}
+
+	// x < min игрок не выбирает, поэтому x = min — отдельная точка
+	rtp := cfg.Min * t.Survival(cfg.Min)
+	for i, v := range values {
+		if v > cfg.Min {
+			rtp = max(rtp, v*min(tail[i]+p[i], 1)) // v_i·P(m >= v_i)
+		}
+	}
+
+	t.pass = cfg.RTP / rtp
+	if !(t.pass <= 1) {
+		return nil, fmt.Errorf("rtp of distribution table %g is less than target %g", rtp, cfg.RTP)
+	}
+
+	t.prob, t.alias = vose(p)
+
+	return t, nil
+}
+
+// vose строит таблицы метода псевдонимов для распределения p.
+func vose(p []float64) (prob []float64, alias []int) {
+	n := len(p)
+	prob = make([]float64, n)
+	alias = make([]int, n)
+
+	scaled := make([]float64, n)
+	var small, large []int
+	for i, pi := range p {
+		scaled[i] = pi * float64(n)
+		if scaled[i] < 1 {
+			small = append(small, i)
+		} else {
+			large = append(large, i)
+		}
+	}
+
+	for len(small) > 0 && len(large) > 0 {
+		s, l := small[len(small)-1], large[len(large)-1]
+		small = small[:len(small)-1]
+
+		prob[s] = scaled[s]
+		alias[s] = l
+
+		scaled[l] -= 1 - scaled[s]
+		if scaled[l] < 1 {
+			large = large[:len(large)-1]
+			small = append(small, l)
+		}
+	}
+
+	// остатки из-за ошибок округления
+	for _, i := range large {
+		prob[i] = 1
+	}
+	for _, i := range small {
+		prob[i] = 1
+	}
+
+	return prob, alias
+}
+
+func (t *table) PassProbability() float64 { return t.pass }
+
+func (t *table) Generate(r *rand.Rand) float64 {
+	i := r.IntN(len(t.prob))
+	if r.Float64() < t.prob[i] {
+		return t.value[i]
+	}
+	return t.value[t.alias[i]]
+}
+
+func (t *table) Survival(x float64) float64 {
+	// первое значение > x
+	i := sort.Search(len(t.value), func(i int) bool { return t.value[i] > x })
+	if i == 0 {
+		return 1
+	}
+	return t.tail[i-1]
+}
+
+// readTable читает таблицу из JSON (массив {"multiplier", "weight"})
+// или CSV (по расширению файла).
+func readTable(path string) ([]tableRow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		var rows []tableRow
+		if err := json.Unmarshal(data, &rows); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return rows, nil
+	}
+
+	rows, err := parseCSVTable(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rows, nil
+}
+
+// parseCSVTable разбирает CSV "multiplier,weight" или "multiplier,cdf".
+// Необязательный заголовок задаёт смысл второй колонки (weight по умолчанию).
+// Если разделитель ';' (экспорт из таблиц с русской локалью), десятичный
+// разделитель может быть запятой.
+func parseCSVTable(data []byte) ([]tableRow, error) {
+	sep := ","
+	if bytes.Contains(data, []byte(";")) {
+		sep = ";"
+	}
+
+	var (
+		rows  []tableRow
+		cdf   bool
+		first = true
+	)
+
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Split(text, sep)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("line %d: want 2 columns, got %d", line, len(fields))
+		}
+
+		m, errM := parseNumber(fields[0], sep)
+		w, errW := parseNumber(fields[1], sep)
+		if first && errM != nil {
+			// заголовок
+			first = false
+			cdf = strings.EqualFold(strings.TrimSpace(fields[1]), "cdf")
+			continue
+		}
+		first = false
+
+		// без текста строки: ошибка может уйти клиенту /admin/config
+		if errM != nil || errW != nil {
+			return nil, fmt.Errorf("line %d: multiplier and weight must be numbers", line)
+		}
+		rows = append(rows, tableRow{m, w})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	if cdf {
+		// CDF -> веса
+		slices.SortFunc(rows, func(a, b tableRow) int { return cmp.Compare(a.Multiplier, b.Multiplier) })
+		var prev float64
+		for i := range rows {
+			if rows[i].Weight < prev {
+				return nil, fmt.Errorf("cdf must be non-decreasing at multiplier %g", rows[i].Multiplier)
+			}
+			rows[i].Weight, prev = rows[i].Weight-prev, rows[i].Weight
+		}
+	}
+
+	return rows, nil
+}
+
+func parseNumber(s, sep string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if sep == ";" {
+		s = strings.Replace(s, ",", ".", 1)
+	}
+	return strconv.ParseFloat(s, 64)
+}
@@ -0,0 +1,66 @@
+package solver
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+)
+
+// paretoTruncated — Pareto(alpha) с точной поправкой на усечение в cfg.Max.
+//
+// Модель выплаты — как в `check -m`: игрок выбирает x и при m > x получает x·m,
+// т.е. RTP(x) = E[m; m > x]. Для неусечённого Pareto(alpha), пропускаемого
+// с вероятностью p, это p·alpha/(alpha-1)·x^(1-alpha). Отсечение хвоста
+// в cfg.Max (M) уменьшает матожидание на одну и ту же величину при любом x < M:
+//
+//	p·M^(1-alpha)/(alpha-1)
+//
+// Недостачу возвращаем дополнительным атомом в M с вероятностью
+// q = p·M^(-alpha)/(alpha-1), забирая её из доли "1" в Solve. Вероятность p
+// выбрана так, чтобы RTP(1) = cfg.RTP, и тогда для всех x в [cfg.Min, M)
+//
+//	RTP(x) = cfg.RTP · x^(1-alpha)
+//
+// ровно, как у неусечённого распределения.
+//
+// Одинаковый cfg.RTP при всех x с формой Pareto(alpha > 1) недостижим: при
+// выплате x·m для этого нужна нулевая плотность на [x, M), при выплате x
+// (как `check -1`) — P(m > x) = RTP/x, т.е. pareto1. Поэтому алгоритм
+// устраняет только смещение от усечения, и cfg.RTP выполняется лишь при x = 1.
+type paretoTruncated struct {
+	alpha    float64
+	min, max float64
+	pass     float64 // p + q
+	atom     float64 // q / (p + q)
+}
+
+func newParetoTruncated(cfg Config) (Generator, error) {
+	if !(cfg.Alpha > 1) {
+		return nil, errors.New("alpha must be > 1: the expected value of Pareto(1) is infinite")
+	}
+
+	a := cfg.Alpha
+	p := cfg.RTP * (a - 1) / a
+	q := p * math.Pow(cfg.Max, -a) / (a - 1)
+
+	return &paretoTruncated{
+		alpha: a,
+		min:   cfg.Min,
+		max:   cfg.Max,
+		pass:  p + q,
+		atom:  q / (p + q),
+	}, nil
+}
+
+func (g *paretoTruncated) PassProbability() float64 { return g.pass }
+
+func (g *paretoTruncated) Survival(x float64) float64 {
+	return g.atom*constSurvival(g.max, x) + (1-g.atom)*paretoSurvival(g.alpha, g.min, g.max, x)
+}
+
+func (g *paretoTruncated) Generate(r *rand.Rand) float64 {
+	if r.Float64() < g.atom {
+		return g.max
+	}
+	return paretoAlpha(r, g.alpha, g.min, g.max)
+}
@@ -0,0 +1,73 @@
+package stats
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// DefaultAccuracy — относительная точность квантилей Sketch.
+const DefaultAccuracy = 0.01
+
+// Sketch — потоковая оценка квантилей с относительной точностью (как DDSketch):
+// значение v попадает в корзину i = ceil(log_gamma(v)), gamma = (1+a)/(1-a),
+// и любой квантиль восстанавливается с ошибкой не больше a·v. Для значений
+// в [1, max] корзин O(log(max)/a), их число фиксировано при создании,
+// поэтому Add — один атомарный инкремент.
+type Sketch struct {
+	gamma    float64
+	logGamma float64
+	buckets  []atomic.Uint64 // buckets[0] — значения <= 1
+	count    atomic.Uint64
+}
+
+// NewSketch создаёт Sketch для значений в [1, max] с относительной точностью accuracy.
+func NewSketch(accuracy, max float64) *Sketch {
+	gamma := (1 + accuracy) / (1 - accuracy)
+	logGamma := math.Log(gamma)
+	n := int(math.Ceil(math.Log(max)/logGamma)) + 1
+	return &Sketch{
+		gamma:    gamma,
+		logGamma: logGamma,
+		buckets:  make([]atomic.Uint64, n),
+	}
+}
+
+func (s *Sketch) index(v float64) int {
+	if !(v > 1) {
+		return 0
+	}
+	i := int(math.Ceil(math.Log(v) / s.logGamma))
+	return min(i, len(s.buckets)-1)
+}
+
+// Add учитывает значение v.
+func (s *Sketch) Add(v float64) {
+	s.buckets[s.index(v)].Add(1)
+	s.count.Add(1)
+}
+
+// Count возвращает число учтённых значений.
+func (s *Sketch) Count() uint64 { return s.count.Load() }
+
+// Quantile возвращает оценку квантиля q в [0, 1] (NaN, если значений нет).
+func (s *Sketch) Quantile(q float64) float64 {
+	count := s.count.Load()
+	if count == 0 {
+		return math.NaN()
+	}
+
+	rank := uint64(q * float64(count-1))
+	var seen uint64
+	for i := range s.buckets {
+		seen += s.buckets[i].Load()
+		if seen > rank {
+			if i == 0 {
+				return 1
+			}
+			// середина корзины (gamma^(i-1), gamma^i] в смысле относительной ошибки
+			return 2 * math.Pow(s.gamma, float64(i)) / (s.gamma + 1)
+		}
+	}
+
+	return 2 * math.Pow(s.gamma, float64(len(s.buckets)-1)) / (s.gamma + 1)
+}
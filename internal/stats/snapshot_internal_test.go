@@ -0,0 +1,23 @@
+package stats
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/aaa2ppp/be"
+)
+
+// Конкурентный Snapshot может застать Observe на середине: count уже
+// увеличен, а sketch ещё нет. Квантилей тогда нет, но и NaN в JSON тоже.
+func TestSnapshot_PartialObserve(t *testing.T) {
+	st := New(1)
+	st.count.Add(1)
+	st.sum.Add(2)
+
+	s := st.Snapshot()
+	be.Equal(t, s.Count, uint64(1))
+	be.Equal(t, len(s.Quantiles), 0)
+
+	_, err := json.Marshal(s)
+	be.Err(t, err, nil)
+}
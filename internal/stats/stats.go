@@ -0,0 +1,186 @@
+// Package stats собирает эмпирическое распределение выданных мультипликаторов
+// с момента старта: число, среднее, максимум, квантили и долю результатов,
+// равных min (доля казино).
+//
+// Как и metrics, учёт мультипликатора — только атомики, без блокировок и аллокаций.
+package stats
+
+import (
+	"math"
+	"sync/atomic"
+
+	"github.com/aaa2ppp/multgen/internal/metrics"
+	"github.com/aaa2ppp/multgen/internal/solver"
+)
+
+// Quantiles — квантили в Snapshot.
+var Quantiles = []struct {
+	Name string
+	Q    float64
+}{
+	{"p50", 0.5},
+	{"p90", 0.9},
+	{"p99", 0.99},
+	{"p999", 0.999},
+}
+
+// SketchMax — верхняя граница квантилей: не зависит от конфигурации солвера,
+// чтобы статистика переживала её смену на лету.
+const SketchMax = 1e12
+
+type Stats struct {
+	min metrics.Float // значение доли казино; меняется при перезагрузке солвера
+
+	count   atomic.Uint64
+	skimmed atomic.Uint64 // результатов <= min
+	sum     metrics.Float
+	max     atomic.Uint64 // math.Float64bits; для m >= 1 порядок битов совпадает с порядком чисел
+
+	sketch *Sketch
+}
+
+// New создаёт статистику; min — мультипликатор, который игрок получает,
+// когда казино забирает свою долю (solver.Config.Min).
+func New(min float64) *Stats {
+	st := &Stats{sketch: NewSketch(DefaultAccuracy, SketchMax)}
+	st.SetMin(min)
+	return st
+}
+
+// SetMin меняет значение доли казино (после смены конфигурации солвера).
+func (st *Stats) SetMin(min float64) {
+	if st == nil {
+		return
+	}
+	st.min.Store(min)
+}
+
+// Observe учитывает выданный мультипликатор m.
+func (st *Stats) Observe(m float64) {
+	if st == nil {
+		return
+	}
+
+	st.count.Add(1)
+	if m <= st.min.Load() {
+		st.skimmed.Add(1)
+	}
+	st.sum.Add(m)
+
+	bits := math.Float64bits(m)
+	for {
+		old := st.max.Load()
+		if bits <= old || st.max.CompareAndSwap(old, bits) {
+			break
+		}
+	}
+
+	st.sketch.Add(m)
+}
+
+// Snapshot — статистика на момент запроса.
+type Snapshot struct {
+	Count     uint64             `json:"count"`
+	Mean      float64            `json:"mean"`
+	Max       float64            `json:"max"`
+	Quantiles map[string]float64 `json:"quantiles"`  // "p50" -> значение
+	SkimShare float64            `json:"skim_share"` // доля результатов, равных min
+}
+
+func (st *Stats) Snapshot() Snapshot {
+	// Observe обновляет count, max и sketch в этом порядке, а читаем мы в
+	// обратном: значение, попавшее в sketch, уже учтено в max и count.
+	// Иначе конкурентный Snapshot мог бы спросить квантили у пустого sketch (NaN).
+	sketched := st.sketch.Count()
+	s := Snapshot{
+		Max:       math.Float64frombits(st.max.Load()),
+		Quantiles: make(map[string]float64, len(Quantiles)),
+	}
+	s.Count = st.count.Load()
+	if s.Count == 0 {
+		return s
+	}
+
+	s.Mean = st.sum.Load() / float64(s.Count)
+	s.SkimShare = float64(st.skimmed.Load()) / float64(s.Count)
+	if sketched > 0 {
+		for _, q := range Quantiles {
+			s.Quantiles[q.Name] = min(st.sketch.Quantile(q.Q), s.Max)
+		}
+	}
+
+	return s
+}
+
+type Solver = metrics.Solver
+
+type instrumented struct {
+	Solver
+	st *Stats
+}
+
+func (s instrumented) Solve() float64 {
+	v := s.Solver.Solve()
+	s.st.Observe(v)
+	return v
+}
+
+func (s instrumented) SolvePrecision() (float64, int) {
+	v, prec := s.Solver.SolvePrecision()
+	s.st.Observe(v)
+	return v, prec
+}
+
+// Instrument оборачивает солвер так, что выданные мультипликаторы попадают
+// в статистику. Для nil-статистики возвращает s как есть.
+func (st *Stats) Instrument(s Solver) Solver {
+	if st == nil {
+		return s
+	}
+	return instrumented{s, st}
+}
+
+// Report — ответ /stats.
+type Report struct {
+	Snapshot
+	Rounds      Rounds       `json:"rounds"`
+	Theoretical *Theoretical `json:"theoretical,omitempty"`
+}
+
+// Rounds — итоги раундов, разыгранных на сервере (/play, /ws, gRPC Play),
+// и фактический RTP (см. solver.Solver.RoundStats).
+type Rounds struct {
+	Count  uint64   `json:"count"`
+	Wins   uint64   `json:"wins"`
+	Bet    float64  `json:"bet"`
+	Payout float64  `json:"payout"`
+	RTP    *float64 `json:"rtp"` // null, если ставок не было
+}
+
+func newRounds(st solver.RoundStats) Rounds {
+	r := Rounds{Count: st.Rounds, Wins: st.Wins, Bet: st.Bet, Payout: st.Payout}
+	if st.Bet > 0 {
+		rtp := st.RTP()
+		r.RTP = &rtp
+	}
+	return r
+}
+
+// Theoretical — теоретический RTP текущей конфигурации солвера для игрока,
+// который всегда выбирает x (см. solver.Solver.TheoreticalRTP).
+type Theoretical struct {
+	X   float64  `json:"x"`
+	RTP *float64 `json:"rtp"` // null, если алгоритм не сообщает своё распределение
+}
+
+// Report собирает статистику, итоги раундов и, если x > 0, теоретический RTP для x.
+func (st *Stats) Report(s Solver, x float64) Report {
+	r := Report{Snapshot: st.Snapshot(), Rounds: newRounds(s.RoundStats())}
+	if x > 0 {
+		r.Theoretical = &Theoretical{X: x}
+		if rtp, ok := s.TheoreticalRTP(x); ok {
+			r.Theoretical.RTP = &rtp
+		}
+	}
+	return r
+}
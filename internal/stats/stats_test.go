@@ -0,0 +1,47 @@
+package stats_test
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/aaa2ppp/be"
+
+	"github.com/aaa2ppp/multgen/internal/stats"
+)
+
+func TestSketch(t *testing.T) {
+	const n = 100_000
+
+	sk := stats.NewSketch(stats.DefaultAccuracy, 10000)
+	values := make([]float64, n)
+	r := rand.New(rand.NewPCG(1, 2))
+	for i := range values {
+		values[i] = min(1/(1-r.Float64()), 10000) // Pareto(1)
+		sk.Add(values[i])
+	}
+
+	for _, q := range []float64{0, 0.5, 0.9, 0.99} {
+		want := 1 / (1 - q) // квантиль Pareto(1)
+		got := sk.Quantile(q)
+		// точность скетча + выборочная ошибка
+		if math.Abs(got-want)/want > 0.05 {
+			t.Errorf("q=%g: got %g, want %g", q, got, want)
+		}
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	st := stats.New(1)
+	for _, m := range []float64{1, 1, 2, 4, 100} {
+		st.Observe(m)
+	}
+
+	s := st.Snapshot()
+	be.Equal(t, s.Count, uint64(5))
+	be.Equal(t, s.Mean, 21.6)
+	be.Equal(t, s.Max, 100.0)
+	be.Equal(t, s.SkimShare, 0.4)
+	be.True(t, math.Abs(s.Quantiles["p50"]-2) <= 2*stats.DefaultAccuracy)
+	be.True(t, math.Abs(s.Quantiles["p999"]-4) <= 4*stats.DefaultAccuracy)
+}
@@ -6,6 +6,8 @@
 // Что здесь:
 //   - Алиасы на internal/config.Config, internal/solver.DefaultConfig etc.
 //   - Функция Main — просто вызывает internal/cmd/multgen.Main.
+//   - RegisterAlgorithm — регистрация собственных алгоритмов из другого модуля
+//     (вызывать до Main, например в init).
 //
 // Никакой логики — только мост.
 package app
@@ -20,7 +22,16 @@ type (
 	Config       = config.Config
 	ServerConfig = config.Server
 	SolverConfig = config.Solver
+
+	Generator        = solver.Generator
+	GeneratorFactory = solver.Factory
+	PassController   = solver.PassController
+	Observer         = solver.Observer
 )
 
 func Main(tune Config)                  { multgen.Main(tune) }
 func DefaultSolverConfig() SolverConfig { return solver.DefaultConfig() }
+
+func RegisterAlgorithm(name, description string, factory GeneratorFactory) {
+	solver.Register(name, description, factory)
+}